@@ -0,0 +1,249 @@
+package resource
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// assetMatcher selects the assets to fetch by glob or regular expression,
+// and keeps track of what each pattern matched so it can be reported.
+type assetMatcher struct {
+	patterns        []assetPattern
+	failOnUnmatched bool
+}
+
+type assetPattern struct {
+	description string
+	matches     func(name string) bool
+	matched     []string
+}
+
+func newAssetMatcher(params InParams) (*assetMatcher, error) {
+	matcher := &assetMatcher{
+		failOnUnmatched: params.AssetRegex != "",
+	}
+
+	if params.FailOnUnmatchedGlobs != nil {
+		matcher.failOnUnmatched = *params.FailOnUnmatchedGlobs
+	}
+
+	for _, glob := range params.Globs {
+		matches, err := compileAssetGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+
+		matcher.patterns = append(matcher.patterns, assetPattern{
+			description: fmt.Sprintf("glob '%s'", glob),
+			matches:     matches,
+		})
+	}
+
+	if params.AssetRegex != "" {
+		re, err := regexp.Compile(params.AssetRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset_regex '%s': %s", params.AssetRegex, err)
+		}
+
+		matcher.patterns = append(matcher.patterns, assetPattern{
+			description: fmt.Sprintf("asset_regex '%s'", params.AssetRegex),
+			matches:     re.MatchString,
+		})
+	}
+
+	return matcher, nil
+}
+
+// selectAssets returns the assets matching any pattern, or every asset if
+// there are no patterns.
+func (m *assetMatcher) selectAssets(assets []*github.ReleaseAsset) []*github.ReleaseAsset {
+	if len(m.patterns) == 0 {
+		return assets
+	}
+
+	selected := []*github.ReleaseAsset{}
+	for _, asset := range assets {
+		found := false
+		for i := range m.patterns {
+			if m.patterns[i].matches(*asset.Name) {
+				m.patterns[i].matched = append(m.patterns[i].matched, *asset.Name)
+				found = true
+			}
+		}
+
+		if found {
+			selected = append(selected, asset)
+		}
+	}
+
+	return selected
+}
+
+func (m *assetMatcher) report(writer io.Writer) {
+	for _, pattern := range m.patterns {
+		if len(pattern.matched) == 0 {
+			fmt.Fprintf(writer, "%s matched no assets\n", pattern.description)
+		} else {
+			fmt.Fprintf(writer, "%s matched: %s\n", pattern.description, strings.Join(pattern.matched, ", "))
+		}
+	}
+}
+
+// unmatched returns an error naming the patterns that matched nothing, if
+// the matcher is configured to fail on them.
+func (m *assetMatcher) unmatched() error {
+	if !m.failOnUnmatched {
+		return nil
+	}
+
+	var unmatched []string
+	for _, pattern := range m.patterns {
+		if len(pattern.matched) == 0 {
+			unmatched = append(unmatched, pattern.description)
+		}
+	}
+
+	if len(unmatched) > 0 {
+		return fmt.Errorf("no assets matched %s", strings.Join(unmatched, ", "))
+	}
+
+	return nil
+}
+
+// assetMode is the permissions given to downloaded assets matching a glob.
+type assetMode struct {
+	glob    string
+	matches func(name string) bool
+	mode    os.FileMode
+}
+
+// newAssetModes compiles the modes globs, along with the executable globs,
+// which are short for a mode of 0755.
+func newAssetModes(params InParams) ([]assetMode, error) {
+	modes := map[string]os.FileMode{}
+	for _, glob := range params.Executable {
+		modes[glob] = 0755
+	}
+
+	for glob, mode := range params.Modes {
+		if existing, found := modes[glob]; found && existing != os.FileMode(mode) {
+			return nil, fmt.Errorf("glob '%s' is given mode %#o by modes but is also executable", glob, mode)
+		}
+
+		modes[glob] = os.FileMode(mode)
+	}
+
+	globs := []string{}
+	for glob := range modes {
+		globs = append(globs, glob)
+	}
+	sort.Strings(globs)
+
+	assetModes := []assetMode{}
+	for _, glob := range globs {
+		matches, err := compileAssetGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+
+		assetModes = append(assetModes, assetMode{
+			glob:    glob,
+			matches: matches,
+			mode:    modes[glob],
+		})
+	}
+
+	return assetModes, nil
+}
+
+// assetModeFor returns the mode the asset should be given, if any. An asset
+// may not match globs giving it different modes.
+func assetModeFor(modes []assetMode, name string) (os.FileMode, bool, error) {
+	var found *assetMode
+	for i, mode := range modes {
+		if !mode.matches(name) {
+			continue
+		}
+
+		if found != nil && found.mode != mode.mode {
+			return 0, false, fmt.Errorf("asset '%s' is given mode %#o by glob '%s' but %#o by glob '%s'", name, found.mode, found.glob, mode.mode, mode.glob)
+		}
+
+		found = &modes[i]
+	}
+
+	if found == nil {
+		return 0, false, nil
+	}
+
+	return found.mode, true, nil
+}
+
+// compileAssetGlob returns a function reporting whether an asset's name
+// matches the glob, which may use {a,b} alternatives and **.
+func compileAssetGlob(glob string) (func(name string) bool, error) {
+	expanded := expandBraces(strings.Replace(glob, "**", "*", -1))
+
+	for _, pattern := range expanded {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob '%s': %s", glob, err)
+		}
+	}
+
+	return func(name string) bool {
+		for _, pattern := range expanded {
+			if ok, _ := filepath.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// expandBraces expands each {a,b} alternation in the pattern, which
+// filepath.Match does not support, into the patterns it stands for.
+func expandBraces(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start == -1 {
+		return []string{pattern}
+	}
+
+	depth := 0
+	alternatives := []string{}
+	last := start + 1
+
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[last:i])
+				last = i + 1
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				alternatives = append(alternatives, pattern[last:i])
+
+				prefix, suffix := pattern[:start], pattern[i+1:]
+
+				expanded := []string{}
+				for _, alternative := range alternatives {
+					expanded = append(expanded, expandBraces(prefix+alternative+suffix)...)
+				}
+				return expanded
+			}
+		}
+	}
+
+	// an unclosed brace is matched literally
+	return []string{pattern}
+}
@@ -0,0 +1,168 @@
+package resource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	// assetUploadWaits is how many times an asset another job is uploading is
+	// waited for before it is assumed to have been abandoned.
+	assetUploadWaits        = 5
+	assetUploadWaitInterval = 5 * time.Second
+)
+
+// uploadAssetsOnly attaches assets to an existing release without changing
+// anything else about it, so that several jobs can each add their own.
+func (c *OutCommand) uploadAssetsOnly(sourceDir string, request OutRequest) (OutResponse, error) {
+	assetPaths, err := c.matchGlobs(sourceDir, request.Params.Globs)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	release, err := c.findRelease(sourceDir, request)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	fmt.Fprintf(c.writer, "adding assets to release %s\n", describeRelease(release))
+
+	uploads := []transferStats{}
+	for _, filePath := range assetPaths {
+		stats, err := c.uploadOwnAsset(release, filePath)
+		if err != nil {
+			return OutResponse{}, err
+		}
+
+		if stats != nil {
+			uploads = append(uploads, *stats)
+		}
+	}
+
+	err = c.refreshAssets(release, uploads)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	metadata := metadataFromRelease(release, "", request.Source.TagFilter)
+	metadata = append(metadata, transferMetadata("uploaded", uploads)...)
+
+	return OutResponse{
+		Version:  versionFromRelease(release, request.Source.DetailedVersions),
+		Metadata: metadata,
+	}, nil
+}
+
+// uploadOwnAsset uploads the file, tolerating other jobs uploading an asset
+// with the same name at the same time. An asset another job is still
+// uploading is waited for, and kept if it turns out to have the same content;
+// nil is returned rather than the upload's stats in that case. Any other
+// asset with the same name is replaced, as is one that has been left
+// half-uploaded. Assets with other names are never touched.
+func (c *OutCommand) uploadOwnAsset(release *github.RepositoryRelease, filePath string) (*transferStats, error) {
+	fmt.Fprintf(c.writer, "uploading %s\n", filePath)
+
+	name := filepath.Base(filePath)
+
+	digest, err := fileDigest(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	waits := 0
+
+	var retryErr error
+	for i := 0; i < 10; i++ {
+		var stats transferStats
+		stats, retryErr = c.uploadFile(release, name, filePath)
+		if retryErr == nil {
+			return &stats, nil
+		}
+
+		assets, err := c.github.ListReleaseAssets(*release)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, asset := range assets {
+			if asset.Name == nil || *asset.Name != name {
+				continue
+			}
+
+			if asset.State == nil || *asset.State != "uploaded" {
+				if waits < assetUploadWaits {
+					fmt.Fprintf(c.writer, "asset %s is still being uploaded; waiting\n", name)
+					waits++
+					c.sleep(assetUploadWaitInterval)
+					break
+				}
+
+				fmt.Fprintf(c.writer, "asset %s was never finished uploading\n", name)
+			} else {
+				same, err := c.assetHasDigest(release, asset, digest)
+				if err != nil {
+					return nil, err
+				}
+
+				if same {
+					fmt.Fprintf(c.writer, "asset %s has already been uploaded\n", name)
+					return nil, nil
+				}
+			}
+
+			fmt.Fprintf(c.writer, "replacing asset %s\n", name)
+
+			err = c.github.DeleteReleaseAsset(*asset)
+			if err != nil {
+				// another job may have removed it first
+				fmt.Fprintf(c.writer, "failed to remove asset %s: %s\n", name, err)
+			}
+
+			break
+		}
+	}
+
+	return nil, retryErr
+}
+
+// assetHasDigest reports whether GitHub's digest of the uploaded asset is the
+// given one. Assets GitHub has no digest for are never considered the same.
+func (c *OutCommand) assetHasDigest(release *github.RepositoryRelease, asset *github.ReleaseAsset, digest string) (bool, error) {
+	details, err := c.github.GetReleaseDetails(*release.ID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, details := range details.Assets {
+		if asset.ID != nil && details.ID == *asset.ID {
+			return details.Digest == digest, nil
+		}
+	}
+
+	return false, nil
+}
+
+// fileDigest returns the file's digest in the form GitHub gives assets'.
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
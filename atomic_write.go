@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeAtomically writes to a temporary file beside the path, and only once
+// write has succeeded is it synced and renamed into place. A failed write
+// leaves nothing at the path, rather than a truncated file that looks right.
+func writeAtomically(path string, write func(io.Writer) error) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		// temporary files are only readable by their owner
+		err = os.Chmod(file.Name(), 0644)
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return nil
+}
+
+// populateAtomically is writeAtomically for directories: populate fills a
+// temporary directory beside the path, which is only renamed into place once
+// populate has succeeded, and is removed otherwise.
+func populateAtomically(path string, populate func(dir string) error) error {
+	dir, err := ioutil.TempDir(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+
+	err = populate(dir)
+
+	if err == nil {
+		// temporary directories are only accessible by their owner
+		err = os.Chmod(dir, 0755)
+	}
+
+	if err == nil {
+		err = os.Rename(dir, path)
+	}
+
+	if err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+
+	return nil
+}
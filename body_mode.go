@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mergeBody combines a release's existing body with the new one according to
+// the body_mode. In section mode the new body replaces whatever is between
+// the two markers for the section, or is added to the end wrapped in them.
+func mergeBody(mode string, section string, existing string, body string) string {
+	switch mode {
+	case "append":
+		return joinBody(existing, body)
+	case "prepend":
+		return joinBody(body, existing)
+	case "section":
+		marker := sectionMarker(section)
+		wrapped := marker + "\n" + body + "\n" + marker
+
+		start := strings.Index(existing, marker)
+		if start == -1 {
+			return joinBody(existing, wrapped)
+		}
+
+		end := strings.Index(existing[start+len(marker):], marker)
+		if end == -1 {
+			return joinBody(existing, wrapped)
+		}
+		end += start + len(marker) + len(marker)
+
+		return existing[:start] + wrapped + existing[end:]
+	default:
+		return body
+	}
+}
+
+func sectionMarker(section string) string {
+	return fmt.Sprintf("<!-- section:%s -->", section)
+}
+
+func joinBody(first string, second string) string {
+	if first == "" {
+		return second
+	}
+
+	if second == "" {
+		return first
+	}
+
+	return first + "\n\n" + second
+}
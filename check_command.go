@@ -1,8 +1,8 @@
 package resource
 
 import (
-	"sort"
-	"strconv"
+	"errors"
+	"time"
 
 	"github.com/google/go-github/github"
 
@@ -10,17 +10,41 @@ import (
 )
 
 type CheckCommand struct {
-	github GitHub
+	github       GitHub
+	repositories map[string]GitHub
+	now          func() time.Time
 }
 
 func NewCheckCommand(github GitHub) *CheckCommand {
+	return NewCheckCommandWithClock(github, time.Now)
+}
+
+func NewCheckCommandWithClock(github GitHub, now func() time.Time) *CheckCommand {
 	return &CheckCommand{
 		github: github,
+		now:    now,
+	}
+}
+
+// NewMultiRepositoryCheckCommand checks the releases of every repository,
+// keyed by their "owner/repository" name, as a single stream of versions.
+func NewMultiRepositoryCheckCommand(repositories map[string]GitHub) *CheckCommand {
+	return NewMultiRepositoryCheckCommandWithClock(repositories, time.Now)
+}
+
+func NewMultiRepositoryCheckCommandWithClock(repositories map[string]GitHub, now func() time.Time) *CheckCommand {
+	return &CheckCommand{
+		repositories: repositories,
+		now:          now,
 	}
 }
 
 func (c *CheckCommand) Run(request CheckRequest) ([]Version, error) {
-	releases, err := c.github.ListReleases()
+	if request.Source.GitHubLatest {
+		return c.checkGitHubLatest(request)
+	}
+
+	releases, origins, err := c.listReleases()
 	if err != nil {
 		return []Version{}, err
 	}
@@ -36,83 +60,167 @@ func (c *CheckCommand) Run(request CheckRequest) ([]Version, error) {
 		return []Version{}, err
 	}
 
-	for _, release := range releases {
-		if request.Source.Drafts != *release.Draft {
-			continue
-		}
-
-		// Should we skip this release
-		//   a- prerelease condition dont match our source config
-		//   b- release condition match  prerealse in github since github has true/false to describe release/prerelase
-		if request.Source.PreRelease != *release.Prerelease && request.Source.Release == *release.Prerelease {
-			continue
-		}
+	releaseFilter, err := newReleaseFilter(request.Source, c.now())
+	if err != nil {
+		return []Version{}, err
+	}
 
-		if release.TagName == nil {
-			continue
-		}
-		if _, err := version.NewVersionFromString(versionParser.parse(*release.TagName)); err != nil {
+	for _, release := range releases {
+		if !releaseIsCheckable(request.Source, versionParser, releaseFilter, release) {
 			continue
 		}
 
 		filteredReleases = append(filteredReleases, release)
 	}
 
-	sort.Slice(filteredReleases, func(i, j int) bool {
-		first, err := version.NewVersionFromString(versionParser.parse(*filteredReleases[i].TagName))
-		if err != nil {
-			return true
-		}
+	if len(filteredReleases) == 0 {
+		return []Version{}, nil
+	}
 
-		second, err := version.NewVersionFromString(versionParser.parse(*filteredReleases[j].TagName))
-		if err != nil {
-			return false
-		}
+	sortReleases(filteredReleases, versionParser)
 
-		return first.IsLt(second)
-	})
+	return versionsSince(filteredReleases, origins, request.Version, request.Source.DetailedVersions), nil
+}
 
-	if len(filteredReleases) == 0 {
+// checkGitHubLatest only ever returns the release that GitHub marks as the
+// repository's latest release, regardless of how it compares to the others,
+// and only if it passes the same filters as any other release.
+func (c *CheckCommand) checkGitHubLatest(request CheckRequest) ([]Version, error) {
+	if len(c.repositories) > 0 {
+		return []Version{}, errors.New("github_latest cannot be used with repositories")
+	}
+
+	latestRelease, err := c.github.GetLatestRelease()
+	if err != nil {
+		return []Version{}, err
+	}
+
+	if latestRelease == nil {
 		return []Version{}, nil
 	}
-	latestRelease := filteredReleases[len(filteredReleases)-1]
 
-	if (request.Version == Version{}) {
-		return []Version{
-			versionFromRelease(latestRelease),
-		}, nil
+	versionParser, err := newVersionParser(request.Source.TagFilter)
+	if err != nil {
+		return []Version{}, err
 	}
 
-	if *latestRelease.TagName == request.Version.Tag {
+	releaseFilter, err := newReleaseFilter(request.Source, c.now())
+	if err != nil {
+		return []Version{}, err
+	}
+
+	if !releaseIsCheckable(request.Source, versionParser, releaseFilter, latestRelease) {
+		return []Version{}, nil
+	}
+
+	latestVersion := versionFromRelease(latestRelease, request.Source.DetailedVersions)
+	if latestVersion == request.Version {
 		return []Version{}, nil
 	}
 
-	upToLatest := false
-	reversedVersions := []Version{}
+	return []Version{latestVersion}, nil
+}
+
+// listReleases returns the releases of every repository along with the
+// repository each of them came from. The origins are left empty when checking
+// a single repository.
+func (c *CheckCommand) listReleases() ([]*github.RepositoryRelease, map[*github.RepositoryRelease]string, error) {
+	origins := map[*github.RepositoryRelease]string{}
+
+	if len(c.repositories) == 0 {
+		releases, err := c.github.ListReleases()
+		return releases, origins, err
+	}
 
-	for _, release := range filteredReleases {
-		if !upToLatest {
-			if *release.Draft || *release.Prerelease {
-				id := *release.ID
-				upToLatest = request.Version.ID == strconv.Itoa(id)
-			} else {
-				version := *release.TagName
-				upToLatest = request.Version.Tag == version
-			}
+	var releases []*github.RepositoryRelease
+	for repository, client := range c.repositories {
+		repositoryReleases, err := client.ListReleases()
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if upToLatest {
-			reversedVersions = append(reversedVersions, versionFromRelease(release))
+		for _, release := range repositoryReleases {
+			origins[release] = repository
 		}
+
+		releases = append(releases, repositoryReleases...)
+	}
+
+	return releases, origins, nil
+}
+
+// versionsSince walks the sorted releases and returns the current version
+// followed by every newer one. If the current version is the latest one
+// nothing is returned, and if it can no longer be found only the latest
+// version is returned.
+func versionsSince(releases []*github.RepositoryRelease, origins map[*github.RepositoryRelease]string, current Version, detailed bool) []Version {
+	versionOf := func(release *github.RepositoryRelease) Version {
+		version := versionFromRelease(release, detailed)
+		version.Repository = origins[release]
+		return version
 	}
 
-	if !upToLatest {
+	latestRelease := releases[len(releases)-1]
+
+	if (current == Version{}) {
+		return []Version{versionOf(latestRelease)}
+	}
+
+	start := indexOfVersion(releases, origins, current)
+	if start == -1 {
 		// current version was removed; start over from latest
-		reversedVersions = append(
-			reversedVersions,
-			versionFromRelease(filteredReleases[len(filteredReleases)-1]),
-		)
+		return []Version{versionOf(latestRelease)}
+	}
+
+	versions := []Version{}
+	for _, release := range releases[start:] {
+		versions = append(versions, versionOf(release))
+	}
+
+	if len(versions) == 1 && versions[0] == current {
+		return []Version{}
+	}
+
+	return versions
+}
+
+// indexOfVersion finds the release a version refers to, by its release ID if
+// it has one or else by its tag. If the version names a repository only
+// releases from that repository are considered.
+func indexOfVersion(releases []*github.RepositoryRelease, origins map[*github.RepositoryRelease]string, current Version) int {
+	fromRepository := func(release *github.RepositoryRelease) bool {
+		return current.Repository == "" || origins[release] == current.Repository
+	}
+
+	for i, release := range releases {
+		if fromRepository(release) && releaseMatchesVersion(release, current) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// releaseIsCheckable reports whether the release is of the type the source
+// checks for, passes its filters, and has a tag with a version.
+func releaseIsCheckable(source Source, versionParser versionParser, releaseFilter releaseFilter, release *github.RepositoryRelease) bool {
+	draft := release.Draft != nil && *release.Draft
+	if source.Drafts != draft {
+		return false
+	}
+
+	if !releaseTypeMatches(source, release) {
+		return false
+	}
+
+	if !releaseFilter.matches(release) {
+		return false
+	}
+
+	if release.TagName == nil {
+		return false
 	}
 
-	return reversedVersions, nil
+	_, err := version.NewVersionFromString(versionParser.parse(*release.TagName))
+	return err == nil
 }
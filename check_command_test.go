@@ -1,6 +1,9 @@
 package resource_test
 
 import (
+	"errors"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -372,6 +375,232 @@ var _ = Describe("Check Command", func() {
 
 			})
 
+			Context("when filtering by author", func() {
+				BeforeEach(func() {
+					returnedReleases = []*github.RepositoryRelease{
+						newRepositoryRelease(1, "v0.1.3"),
+						newRepositoryRelease(2, "v0.1.4"),
+						newRepositoryRelease(3, "v0.2.0"),
+						newRepositoryRelease(4, "v0.3.0"),
+					}
+					returnedReleases[0].Author = &github.User{Login: github.String("release-bot")}
+					returnedReleases[1].Author = &github.User{Login: github.String("some-human")}
+					returnedReleases[2].Author = &github.User{Login: github.String("other-bot")}
+				})
+
+				It("returns only the versions released by one of the authors", func() {
+					command := resource.NewCheckCommand(githubClient)
+
+					response, err := command.Run(resource.CheckRequest{
+						Version: resource.Version{Tag: "v0.1.3"},
+						Source: resource.Source{
+							Release: true,
+							Authors: []string{"release-bot", "other-bot"},
+						},
+					})
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(response).Should(Equal([]resource.Version{
+						{Tag: "v0.1.3"},
+						{Tag: "v0.2.0"},
+					}))
+				})
+			})
+
+			Context("when filtering by target commitish", func() {
+				BeforeEach(func() {
+					returnedReleases = []*github.RepositoryRelease{
+						newRepositoryRelease(1, "v0.1.3"),
+						newRepositoryRelease(2, "v0.1.4"),
+						newRepositoryRelease(3, "v0.2.0"),
+						newRepositoryRelease(4, "v0.3.0"),
+					}
+					returnedReleases[0].TargetCommitish = github.String("main")
+					returnedReleases[1].TargetCommitish = github.String("feature/thing")
+					returnedReleases[2].TargetCommitish = github.String("release/0.2")
+				})
+
+				It("returns only the versions cut from a matching commitish", func() {
+					command := resource.NewCheckCommand(githubClient)
+
+					response, err := command.Run(resource.CheckRequest{
+						Version: resource.Version{Tag: "v0.1.3"},
+						Source: resource.Source{
+							Release:         true,
+							CommitishFilter: "^(main|release/.*)$",
+						},
+					})
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(response).Should(Equal([]resource.Version{
+						{Tag: "v0.1.3"},
+						{Tag: "v0.2.0"},
+					}))
+				})
+
+				It("returns an error if the filter is not a valid regular expression", func() {
+					command := resource.NewCheckCommand(githubClient)
+
+					_, err := command.Run(resource.CheckRequest{
+						Source: resource.Source{
+							Release:         true,
+							CommitishFilter: "(main",
+						},
+					})
+					Ω(err).Should(HaveOccurred())
+				})
+			})
+
+			Context("when excluding by body", func() {
+				BeforeEach(func() {
+					returnedReleases = []*github.RepositoryRelease{
+						newRepositoryRelease(1, "v0.1.3"),
+						newRepositoryRelease(2, "v0.1.4"),
+						newRepositoryRelease(3, "v0.2.0"),
+					}
+					returnedReleases[1].Body = github.String("this one is broken. DO NOT USE")
+					returnedReleases[2].Body = github.String("all good")
+				})
+
+				It("skips the versions whose body matches", func() {
+					command := resource.NewCheckCommand(githubClient)
+
+					response, err := command.Run(resource.CheckRequest{
+						Version: resource.Version{Tag: "v0.1.3"},
+						Source: resource.Source{
+							Release:           true,
+							BodyExcludeFilter: "DO NOT USE",
+						},
+					})
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(response).Should(Equal([]resource.Version{
+						{Tag: "v0.1.3"},
+						{Tag: "v0.2.0"},
+					}))
+				})
+			})
+
+			Context("when a minimum age is configured", func() {
+				var now time.Time
+
+				BeforeEach(func() {
+					now = time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+					returnedReleases = []*github.RepositoryRelease{
+						newRepositoryRelease(1, "v0.1.3"),
+						newRepositoryRelease(2, "v0.1.4"),
+						newRepositoryRelease(3, "v0.2.0"),
+						newRepositoryRelease(4, "v0.3.0"),
+					}
+					returnedReleases[0].PublishedAt = &github.Timestamp{Time: now.Add(-72 * time.Hour)}
+					returnedReleases[1].PublishedAt = &github.Timestamp{Time: now.Add(-25 * time.Hour)}
+					returnedReleases[2].PublishedAt = &github.Timestamp{Time: now.Add(-23 * time.Hour)}
+				})
+
+				It("returns only the versions that were published before the window", func() {
+					command := resource.NewCheckCommandWithClock(githubClient, func() time.Time { return now })
+
+					response, err := command.Run(resource.CheckRequest{
+						Version: resource.Version{Tag: "v0.1.3"},
+						Source:  resource.Source{Release: true, MinAge: "24h"},
+					})
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(response).Should(Equal([]resource.Version{
+						{Tag: "v0.1.3"},
+						{Tag: "v0.1.4"},
+					}))
+				})
+
+				It("returns an error if the minimum age is not a duration", func() {
+					command := resource.NewCheckCommandWithClock(githubClient, func() time.Time { return now })
+
+					_, err := command.Run(resource.CheckRequest{
+						Source: resource.Source{Release: true, MinAge: "a day"},
+					})
+					Ω(err).Should(HaveOccurred())
+				})
+			})
+
+			Context("when detailed versions are enabled", func() {
+				var publishedAt time.Time
+
+				BeforeEach(func() {
+					publishedAt = time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+					returnedReleases = []*github.RepositoryRelease{
+						newRepositoryRelease(1, "v0.1.3"),
+						newRepositoryRelease(2, "v0.1.4"),
+						newRepositoryRelease(3, "v0.2.0"),
+					}
+					for _, release := range returnedReleases {
+						release.PublishedAt = &github.Timestamp{Time: publishedAt}
+					}
+					returnedReleases[2].TargetCommitish = github.String("f28085a4a8f744da83411f5e09fd7b1709149eee")
+				})
+
+				It("returns the tag, ID, publish time and commit of every version", func() {
+					command := resource.NewCheckCommand(githubClient)
+
+					response, err := command.Run(resource.CheckRequest{
+						Version: resource.Version{Tag: "v0.1.4", ID: "2", PublishedAt: "2018-01-10T12:00:00Z"},
+						Source:  resource.Source{Release: true, DetailedVersions: true},
+					})
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(response).Should(Equal([]resource.Version{
+						{Tag: "v0.1.4", ID: "2", PublishedAt: "2018-01-10T12:00:00Z"},
+						{Tag: "v0.2.0", ID: "3", PublishedAt: "2018-01-10T12:00:00Z", CommitSHA: "f28085a4a8f744da83411f5e09fd7b1709149eee"},
+					}))
+				})
+
+				It("continues from a version that only has a tag", func() {
+					command := resource.NewCheckCommand(githubClient)
+
+					response, err := command.Run(resource.CheckRequest{
+						Version: resource.Version{Tag: "v0.1.4"},
+						Source:  resource.Source{Release: true, DetailedVersions: true},
+					})
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(response).Should(Equal([]resource.Version{
+						{Tag: "v0.1.4", ID: "2", PublishedAt: "2018-01-10T12:00:00Z"},
+						{Tag: "v0.2.0", ID: "3", PublishedAt: "2018-01-10T12:00:00Z", CommitSHA: "f28085a4a8f744da83411f5e09fd7b1709149eee"},
+					}))
+				})
+
+				It("starts over from the latest release when the version's release was recreated with the same tag", func() {
+					command := resource.NewCheckCommand(githubClient)
+
+					response, err := command.Run(resource.CheckRequest{
+						Version: resource.Version{Tag: "v0.1.4", ID: "9", PublishedAt: "2018-01-10T12:00:00Z"},
+						Source:  resource.Source{Release: true, DetailedVersions: true},
+					})
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(response).Should(Equal([]resource.Version{
+						{Tag: "v0.2.0", ID: "3", PublishedAt: "2018-01-10T12:00:00Z", CommitSHA: "f28085a4a8f744da83411f5e09fd7b1709149eee"},
+					}))
+				})
+
+				It("returns a release that was recreated with the same tag", func() {
+					returnedReleases[2].ID = github.Int(4)
+
+					command := resource.NewCheckCommand(githubClient)
+
+					response, err := command.Run(resource.CheckRequest{
+						Version: resource.Version{Tag: "v0.2.0", ID: "3", PublishedAt: "2018-01-10T12:00:00Z", CommitSHA: "f28085a4a8f744da83411f5e09fd7b1709149eee"},
+						Source:  resource.Source{Release: true, DetailedVersions: true},
+					})
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(response).Should(Equal([]resource.Version{
+						{Tag: "v0.2.0", ID: "4", PublishedAt: "2018-01-10T12:00:00Z", CommitSHA: "f28085a4a8f744da83411f5e09fd7b1709149eee"},
+					}))
+				})
+			})
+
 			Context("when draft releases are allowed", func() {
 				Context("and one of the releases is a final release", func() {
 					BeforeEach(func() {
@@ -461,4 +690,287 @@ var _ = Describe("Check Command", func() {
 			})
 		})
 	})
+
+	Context("when following GitHub's latest release", func() {
+		BeforeEach(func() {
+			returnedReleases = []*github.RepositoryRelease{
+				newRepositoryRelease(1, "v0.4.0"),
+				newRepositoryRelease(2, "v0.3.0"),
+			}
+
+			githubClient.GetLatestReleaseReturns(newRepositoryRelease(2, "v0.3.0"), nil)
+		})
+
+		It("returns the latest release even if a higher version exists", func() {
+			response, err := command.Run(resource.CheckRequest{
+				Source: resource.Source{GitHubLatest: true},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{{Tag: "v0.3.0"}}))
+			Ω(githubClient.ListReleasesCallCount()).Should(Equal(0))
+		})
+
+		It("returns nothing if the latest release has not changed", func() {
+			response, err := command.Run(resource.CheckRequest{
+				Version: resource.Version{Tag: "v0.3.0"},
+				Source:  resource.Source{GitHubLatest: true},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(BeEmpty())
+		})
+
+		It("returns nothing if the repository has no releases", func() {
+			githubClient.GetLatestReleaseReturns(nil, nil)
+
+			response, err := command.Run(resource.CheckRequest{
+				Source: resource.Source{GitHubLatest: true},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(BeEmpty())
+		})
+
+		It("returns nothing if the latest release does not pass the filters", func() {
+			response, err := command.Run(resource.CheckRequest{
+				Source: resource.Source{GitHubLatest: true, TagFilter: "^v0\\.4\\.(.*)$"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(response).Should(BeEmpty())
+
+			response, err = command.Run(resource.CheckRequest{
+				Source: resource.Source{GitHubLatest: true, Authors: []string{"someone"}},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(response).Should(BeEmpty())
+
+			response, err = command.Run(resource.CheckRequest{
+				Source: resource.Source{GitHubLatest: true, Drafts: true},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(response).Should(BeEmpty())
+		})
+
+		It("returns an error if getting the latest release fails", func() {
+			githubClient.GetLatestReleaseReturns(nil, errors.New("disaster"))
+
+			_, err := command.Run(resource.CheckRequest{
+				Source: resource.Source{GitHubLatest: true},
+			})
+			Ω(err).Should(MatchError("disaster"))
+		})
+	})
+
+	Context("when checking multiple repositories", func() {
+		var (
+			upstreamClient *fakes.FakeGitHub
+			forkClient     *fakes.FakeGitHub
+		)
+
+		BeforeEach(func() {
+			upstreamClient = &fakes.FakeGitHub{}
+			upstreamClient.ListReleasesReturns([]*github.RepositoryRelease{
+				newRepositoryRelease(1, "v0.1.0"),
+				newRepositoryRelease(2, "v0.3.0"),
+			}, nil)
+
+			forkClient = &fakes.FakeGitHub{}
+			forkClient.ListReleasesReturns([]*github.RepositoryRelease{
+				newRepositoryRelease(3, "v0.2.0"),
+				newRepositoryRelease(4, "v0.4.0"),
+			}, nil)
+
+			command = resource.NewMultiRepositoryCheckCommand(map[string]resource.GitHub{
+				"concourse/concourse": upstreamClient,
+				"some-fork/concourse": forkClient,
+			})
+		})
+
+		It("returns the latest version across all repositories", func() {
+			response, err := command.Run(resource.CheckRequest{
+				Source: resource.Source{Release: true},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{Tag: "v0.4.0", Repository: "some-fork/concourse"},
+			}))
+		})
+
+		It("returns the newer versions of every repository in order", func() {
+			response, err := command.Run(resource.CheckRequest{
+				Version: resource.Version{Tag: "v0.1.0", Repository: "concourse/concourse"},
+				Source:  resource.Source{Release: true},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{Tag: "v0.1.0", Repository: "concourse/concourse"},
+				{Tag: "v0.2.0", Repository: "some-fork/concourse"},
+				{Tag: "v0.3.0", Repository: "concourse/concourse"},
+				{Tag: "v0.4.0", Repository: "some-fork/concourse"},
+			}))
+		})
+
+		It("leaves out versions of any repository that are younger than the minimum age", func() {
+			now := time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+			upstreamReleases := []*github.RepositoryRelease{
+				newRepositoryRelease(1, "v0.1.0"),
+				newRepositoryRelease(2, "v0.3.0"),
+			}
+			upstreamReleases[0].PublishedAt = &github.Timestamp{Time: now.Add(-72 * time.Hour)}
+			upstreamReleases[1].PublishedAt = &github.Timestamp{Time: now.Add(-23 * time.Hour)}
+			upstreamClient.ListReleasesReturns(upstreamReleases, nil)
+
+			forkReleases := []*github.RepositoryRelease{
+				newRepositoryRelease(3, "v0.2.0"),
+				newRepositoryRelease(4, "v0.4.0"),
+			}
+			forkReleases[0].PublishedAt = &github.Timestamp{Time: now.Add(-25 * time.Hour)}
+			forkReleases[1].PublishedAt = &github.Timestamp{Time: now.Add(-1 * time.Hour)}
+			forkClient.ListReleasesReturns(forkReleases, nil)
+
+			command = resource.NewMultiRepositoryCheckCommandWithClock(map[string]resource.GitHub{
+				"concourse/concourse": upstreamClient,
+				"some-fork/concourse": forkClient,
+			}, func() time.Time { return now })
+
+			response, err := command.Run(resource.CheckRequest{
+				Version: resource.Version{Tag: "v0.1.0", Repository: "concourse/concourse"},
+				Source:  resource.Source{Release: true, MinAge: "24h"},
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response).Should(Equal([]resource.Version{
+				{Tag: "v0.1.0", Repository: "concourse/concourse"},
+				{Tag: "v0.2.0", Repository: "some-fork/concourse"},
+			}))
+		})
+
+		It("returns an error if listing the releases of any repository fails", func() {
+			forkClient.ListReleasesReturns(nil, errors.New("disaster"))
+
+			_, err := command.Run(resource.CheckRequest{
+				Source: resource.Source{Release: true},
+			})
+			Ω(err).Should(MatchError("disaster"))
+		})
+	})
+
+	Describe("walking from the current version", func() {
+		type walkEntry struct {
+			description string
+			source      resource.Source
+			releases    []*github.RepositoryRelease
+			current     resource.Version
+			expected    []resource.Version
+		}
+
+		mixedReleases := func() []*github.RepositoryRelease {
+			return []*github.RepositoryRelease{
+				newDraftRepositoryRelease(10, "v0.5.0"),
+				newRepositoryRelease(1, "v0.1.0"),
+				newPreReleaseRepositoryRelease(2, "v0.2.0-rc.1"),
+				newRepositoryRelease(3, "v0.2.0"),
+				newPreReleaseRepositoryRelease(4, "v0.3.0-rc.1"),
+				newPreReleaseRepositoryRelease(5, "v0.3.0-rc.2"),
+				newDraftRepositoryRelease(11, "v0.4.0"),
+			}
+		}
+
+		entries := []walkEntry{
+			{
+				description: "releases only, from a release",
+				source:      resource.Source{Release: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{Tag: "v0.1.0"},
+				expected:    []resource.Version{{Tag: "v0.1.0"}, {Tag: "v0.2.0"}},
+			},
+			{
+				description: "pre-releases only, from a pre-release",
+				source:      resource.Source{PreRelease: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{Tag: "v0.2.0-rc.1"},
+				expected:    []resource.Version{{Tag: "v0.2.0-rc.1"}, {Tag: "v0.3.0-rc.1"}, {Tag: "v0.3.0-rc.2"}},
+			},
+			{
+				description: "pre-releases only, from a pre-release identified by ID",
+				source:      resource.Source{PreRelease: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{ID: "4"},
+				expected:    []resource.Version{{Tag: "v0.3.0-rc.1"}, {Tag: "v0.3.0-rc.2"}},
+			},
+			{
+				description: "releases and pre-releases, from a pre-release",
+				source:      resource.Source{Release: true, PreRelease: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{Tag: "v0.2.0-rc.1"},
+				expected:    []resource.Version{{Tag: "v0.2.0-rc.1"}, {Tag: "v0.2.0"}, {Tag: "v0.3.0-rc.1"}, {Tag: "v0.3.0-rc.2"}},
+			},
+			{
+				description: "releases and pre-releases, from a release",
+				source:      resource.Source{Release: true, PreRelease: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{Tag: "v0.2.0"},
+				expected:    []resource.Version{{Tag: "v0.2.0"}, {Tag: "v0.3.0-rc.1"}, {Tag: "v0.3.0-rc.2"}},
+			},
+			{
+				description: "releases and pre-releases, from the latest version",
+				source:      resource.Source{Release: true, PreRelease: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{Tag: "v0.3.0-rc.2"},
+				expected:    []resource.Version{},
+			},
+			{
+				description: "releases and pre-releases, from a version that was removed",
+				source:      resource.Source{Release: true, PreRelease: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{Tag: "v0.2.1"},
+				expected:    []resource.Version{{Tag: "v0.3.0-rc.2"}},
+			},
+			{
+				description: "drafts, from a draft",
+				source:      resource.Source{Drafts: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{ID: "11"},
+				expected:    []resource.Version{{ID: "11"}, {ID: "10"}},
+			},
+			{
+				description: "drafts, from the latest draft",
+				source:      resource.Source{Drafts: true},
+				releases:    mixedReleases(),
+				current:     resource.Version{ID: "10"},
+				expected:    []resource.Version{},
+			},
+			{
+				description: "detailed versions, from a version with the same tag as another release",
+				source:      resource.Source{Release: true, PreRelease: true, DetailedVersions: true},
+				releases: []*github.RepositoryRelease{
+					newRepositoryRelease(1, "v0.1.0"),
+					newRepositoryRelease(2, "0.1.0"),
+					newRepositoryRelease(3, "v0.2.0"),
+				},
+				current:  resource.Version{Tag: "0.1.0", ID: "2"},
+				expected: []resource.Version{{Tag: "0.1.0", ID: "2"}, {Tag: "v0.2.0", ID: "3"}},
+			},
+		}
+
+		for _, entry := range entries {
+			entry := entry
+
+			It("returns every newer version in order for "+entry.description, func() {
+				githubClient.ListReleasesReturns(entry.releases, nil)
+
+				response, err := command.Run(resource.CheckRequest{
+					Version: entry.current,
+					Source:  entry.source,
+				})
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(response).Should(Equal(entry.expected))
+			})
+		}
+	})
 })
@@ -11,12 +11,23 @@ func main() {
 	request := resource.NewCheckRequest()
 	inputRequest(&request)
 
-	github, err := resource.NewGitHubClient(request.Source)
-	if err != nil {
-		resource.Fatal("constructing github client", err)
+	var command *resource.CheckCommand
+	if len(request.Source.Repositories) > 0 {
+		clients, err := resource.NewGitHubClients(request.Source)
+		if err != nil {
+			resource.Fatal("constructing github clients", err)
+		}
+
+		command = resource.NewMultiRepositoryCheckCommand(clients)
+	} else {
+		github, err := resource.NewGitHubClient(request.Source)
+		if err != nil {
+			resource.Fatal("constructing github client", err)
+		}
+
+		command = resource.NewCheckCommand(github)
 	}
 
-	command := resource.NewCheckCommand(github)
 	response, err := command.Run(request)
 	if err != nil {
 		resource.Fatal("running command", err)
@@ -18,7 +18,12 @@ func main() {
 
 	destDir := os.Args[1]
 
-	github, err := resource.NewGitHubClient(request.Source)
+	var repository string
+	if request.Version != nil {
+		repository = request.Version.Repository
+	}
+
+	github, err := resource.NewGitHubClientForRepository(request.Source, repository)
 	if err != nil {
 		resource.Fatal("constructing github client", err)
 	}
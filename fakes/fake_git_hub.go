@@ -4,7 +4,6 @@ package fakes
 import (
 	"io"
 	"net/url"
-	"os"
 	"sync"
 
 	"github.com/concourse/github-release-resource"
@@ -19,6 +18,13 @@ type FakeGitHub struct {
 		result1 []*github.RepositoryRelease
 		result2 error
 	}
+	ListAllReleasesStub        func() ([]*github.RepositoryRelease, error)
+	listAllReleasesMutex       sync.RWMutex
+	listAllReleasesArgsForCall []struct{}
+	listAllReleasesReturns     struct {
+		result1 []*github.RepositoryRelease
+		result2 error
+	}
 	GetReleaseByTagStub        func(tag string) (*github.RepositoryRelease, error)
 	getReleaseByTagMutex       sync.RWMutex
 	getReleaseByTagArgsForCall []struct {
@@ -37,24 +43,50 @@ type FakeGitHub struct {
 		result1 *github.RepositoryRelease
 		result2 error
 	}
-	CreateReleaseStub        func(release github.RepositoryRelease) (*github.RepositoryRelease, error)
+	GetLatestReleaseStub        func() (*github.RepositoryRelease, error)
+	getLatestReleaseMutex       sync.RWMutex
+	getLatestReleaseArgsForCall []struct{}
+	getLatestReleaseReturns     struct {
+		result1 *github.RepositoryRelease
+		result2 error
+	}
+	GetReleaseDetailsStub        func(id int) (*resource.ReleaseDetails, error)
+	getReleaseDetailsMutex       sync.RWMutex
+	getReleaseDetailsArgsForCall []struct {
+		id int
+	}
+	getReleaseDetailsReturns struct {
+		result1 *resource.ReleaseDetails
+		result2 error
+	}
+	CreateReleaseStub        func(release github.RepositoryRelease, options resource.ReleaseOptions) (*github.RepositoryRelease, error)
 	createReleaseMutex       sync.RWMutex
 	createReleaseArgsForCall []struct {
 		release github.RepositoryRelease
+		options resource.ReleaseOptions
 	}
 	createReleaseReturns struct {
 		result1 *github.RepositoryRelease
 		result2 error
 	}
-	UpdateReleaseStub        func(release github.RepositoryRelease) (*github.RepositoryRelease, error)
+	UpdateReleaseStub        func(release github.RepositoryRelease, options resource.ReleaseOptions) (*github.RepositoryRelease, error)
 	updateReleaseMutex       sync.RWMutex
 	updateReleaseArgsForCall []struct {
 		release github.RepositoryRelease
+		options resource.ReleaseOptions
 	}
 	updateReleaseReturns struct {
 		result1 *github.RepositoryRelease
 		result2 error
 	}
+	DeleteReleaseStub        func(release github.RepositoryRelease) error
+	deleteReleaseMutex       sync.RWMutex
+	deleteReleaseArgsForCall []struct {
+		release github.RepositoryRelease
+	}
+	deleteReleaseReturns struct {
+		result1 error
+	}
 	ListReleaseAssetsStub        func(release github.RepositoryRelease) ([]*github.ReleaseAsset, error)
 	listReleaseAssetsMutex       sync.RWMutex
 	listReleaseAssetsArgsForCall []struct {
@@ -64,12 +96,13 @@ type FakeGitHub struct {
 		result1 []*github.ReleaseAsset
 		result2 error
 	}
-	UploadReleaseAssetStub        func(release github.RepositoryRelease, name string, file *os.File) error
+	UploadReleaseAssetStub        func(release github.RepositoryRelease, name string, content io.Reader, size int64) error
 	uploadReleaseAssetMutex       sync.RWMutex
 	uploadReleaseAssetArgsForCall []struct {
 		release github.RepositoryRelease
 		name    string
-		file    *os.File
+		content io.Reader
+		size    int64
 	}
 	uploadReleaseAssetReturns struct {
 		result1 error
@@ -118,6 +151,77 @@ type FakeGitHub struct {
 		result1 *github.Reference
 		result2 error
 	}
+	GetTagStub        func(sha string) (*github.Tag, error)
+	getTagMutex       sync.RWMutex
+	getTagArgsForCall []struct {
+		sha string
+	}
+	getTagReturns struct {
+		result1 *github.Tag
+		result2 error
+	}
+	CreateTagStub        func(tag github.Tag) (*github.Tag, error)
+	createTagMutex       sync.RWMutex
+	createTagArgsForCall []struct {
+		tag github.Tag
+	}
+	createTagReturns struct {
+		result1 *github.Tag
+		result2 error
+	}
+	CreateRefStub        func(tag string, sha string) (*github.Reference, error)
+	createRefMutex       sync.RWMutex
+	createRefArgsForCall []struct {
+		tag string
+		sha string
+	}
+	createRefReturns struct {
+		result1 *github.Reference
+		result2 error
+	}
+	DeleteRefStub        func(tag string) error
+	deleteRefMutex       sync.RWMutex
+	deleteRefArgsForCall []struct {
+		tag string
+	}
+	deleteRefReturns struct {
+		result1 error
+	}
+	GetDefaultBranchStub        func() (string, error)
+	getDefaultBranchMutex       sync.RWMutex
+	getDefaultBranchArgsForCall []struct{}
+	getDefaultBranchReturns     struct {
+		result1 string
+		result2 error
+	}
+	CompareCommitsStub        func(base string, head string) (*github.CommitsComparison, error)
+	compareCommitsMutex       sync.RWMutex
+	compareCommitsArgsForCall []struct {
+		base string
+		head string
+	}
+	compareCommitsReturns struct {
+		result1 *github.CommitsComparison
+		result2 error
+	}
+	GetIssueStub        func(number int) (*github.Issue, error)
+	getIssueMutex       sync.RWMutex
+	getIssueArgsForCall []struct {
+		number int
+	}
+	getIssueReturns struct {
+		result1 *github.Issue
+		result2 error
+	}
+	CloneRefStub        func(ref string, dir string) error
+	cloneRefMutex       sync.RWMutex
+	cloneRefArgsForCall []struct {
+		ref string
+		dir string
+	}
+	cloneRefReturns struct {
+		result1 error
+	}
 }
 
 func (fake *FakeGitHub) ListReleases() ([]*github.RepositoryRelease, error) {
@@ -145,6 +249,31 @@ func (fake *FakeGitHub) ListReleasesReturns(result1 []*github.RepositoryRelease,
 	}{result1, result2}
 }
 
+func (fake *FakeGitHub) ListAllReleases() ([]*github.RepositoryRelease, error) {
+	fake.listAllReleasesMutex.Lock()
+	fake.listAllReleasesArgsForCall = append(fake.listAllReleasesArgsForCall, struct{}{})
+	fake.listAllReleasesMutex.Unlock()
+	if fake.ListAllReleasesStub != nil {
+		return fake.ListAllReleasesStub()
+	} else {
+		return fake.listAllReleasesReturns.result1, fake.listAllReleasesReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) ListAllReleasesCallCount() int {
+	fake.listAllReleasesMutex.RLock()
+	defer fake.listAllReleasesMutex.RUnlock()
+	return len(fake.listAllReleasesArgsForCall)
+}
+
+func (fake *FakeGitHub) ListAllReleasesReturns(result1 []*github.RepositoryRelease, result2 error) {
+	fake.ListAllReleasesStub = nil
+	fake.listAllReleasesReturns = struct {
+		result1 []*github.RepositoryRelease
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGitHub) GetReleaseByTag(tag string) (*github.RepositoryRelease, error) {
 	fake.getReleaseByTagMutex.Lock()
 	fake.getReleaseByTagArgsForCall = append(fake.getReleaseByTagArgsForCall, struct {
@@ -211,14 +340,73 @@ func (fake *FakeGitHub) GetReleaseReturns(result1 *github.RepositoryRelease, res
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) CreateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error) {
+func (fake *FakeGitHub) GetLatestRelease() (*github.RepositoryRelease, error) {
+	fake.getLatestReleaseMutex.Lock()
+	fake.getLatestReleaseArgsForCall = append(fake.getLatestReleaseArgsForCall, struct{}{})
+	fake.getLatestReleaseMutex.Unlock()
+	if fake.GetLatestReleaseStub != nil {
+		return fake.GetLatestReleaseStub()
+	} else {
+		return fake.getLatestReleaseReturns.result1, fake.getLatestReleaseReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) GetLatestReleaseCallCount() int {
+	fake.getLatestReleaseMutex.RLock()
+	defer fake.getLatestReleaseMutex.RUnlock()
+	return len(fake.getLatestReleaseArgsForCall)
+}
+
+func (fake *FakeGitHub) GetLatestReleaseReturns(result1 *github.RepositoryRelease, result2 error) {
+	fake.GetLatestReleaseStub = nil
+	fake.getLatestReleaseReturns = struct {
+		result1 *github.RepositoryRelease
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) GetReleaseDetails(id int) (*resource.ReleaseDetails, error) {
+	fake.getReleaseDetailsMutex.Lock()
+	fake.getReleaseDetailsArgsForCall = append(fake.getReleaseDetailsArgsForCall, struct {
+		id int
+	}{id})
+	fake.getReleaseDetailsMutex.Unlock()
+	if fake.GetReleaseDetailsStub != nil {
+		return fake.GetReleaseDetailsStub(id)
+	} else {
+		return fake.getReleaseDetailsReturns.result1, fake.getReleaseDetailsReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) GetReleaseDetailsCallCount() int {
+	fake.getReleaseDetailsMutex.RLock()
+	defer fake.getReleaseDetailsMutex.RUnlock()
+	return len(fake.getReleaseDetailsArgsForCall)
+}
+
+func (fake *FakeGitHub) GetReleaseDetailsArgsForCall(i int) int {
+	fake.getReleaseDetailsMutex.RLock()
+	defer fake.getReleaseDetailsMutex.RUnlock()
+	return fake.getReleaseDetailsArgsForCall[i].id
+}
+
+func (fake *FakeGitHub) GetReleaseDetailsReturns(result1 *resource.ReleaseDetails, result2 error) {
+	fake.GetReleaseDetailsStub = nil
+	fake.getReleaseDetailsReturns = struct {
+		result1 *resource.ReleaseDetails
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) CreateRelease(release github.RepositoryRelease, options resource.ReleaseOptions) (*github.RepositoryRelease, error) {
 	fake.createReleaseMutex.Lock()
 	fake.createReleaseArgsForCall = append(fake.createReleaseArgsForCall, struct {
 		release github.RepositoryRelease
-	}{release})
+		options resource.ReleaseOptions
+	}{release, options})
 	fake.createReleaseMutex.Unlock()
 	if fake.CreateReleaseStub != nil {
-		return fake.CreateReleaseStub(release)
+		return fake.CreateReleaseStub(release, options)
 	} else {
 		return fake.createReleaseReturns.result1, fake.createReleaseReturns.result2
 	}
@@ -230,10 +418,10 @@ func (fake *FakeGitHub) CreateReleaseCallCount() int {
 	return len(fake.createReleaseArgsForCall)
 }
 
-func (fake *FakeGitHub) CreateReleaseArgsForCall(i int) github.RepositoryRelease {
+func (fake *FakeGitHub) CreateReleaseArgsForCall(i int) (github.RepositoryRelease, resource.ReleaseOptions) {
 	fake.createReleaseMutex.RLock()
 	defer fake.createReleaseMutex.RUnlock()
-	return fake.createReleaseArgsForCall[i].release
+	return fake.createReleaseArgsForCall[i].release, fake.createReleaseArgsForCall[i].options
 }
 
 func (fake *FakeGitHub) CreateReleaseReturns(result1 *github.RepositoryRelease, result2 error) {
@@ -244,14 +432,15 @@ func (fake *FakeGitHub) CreateReleaseReturns(result1 *github.RepositoryRelease,
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) UpdateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error) {
+func (fake *FakeGitHub) UpdateRelease(release github.RepositoryRelease, options resource.ReleaseOptions) (*github.RepositoryRelease, error) {
 	fake.updateReleaseMutex.Lock()
 	fake.updateReleaseArgsForCall = append(fake.updateReleaseArgsForCall, struct {
 		release github.RepositoryRelease
-	}{release})
+		options resource.ReleaseOptions
+	}{release, options})
 	fake.updateReleaseMutex.Unlock()
 	if fake.UpdateReleaseStub != nil {
-		return fake.UpdateReleaseStub(release)
+		return fake.UpdateReleaseStub(release, options)
 	} else {
 		return fake.updateReleaseReturns.result1, fake.updateReleaseReturns.result2
 	}
@@ -263,10 +452,10 @@ func (fake *FakeGitHub) UpdateReleaseCallCount() int {
 	return len(fake.updateReleaseArgsForCall)
 }
 
-func (fake *FakeGitHub) UpdateReleaseArgsForCall(i int) github.RepositoryRelease {
+func (fake *FakeGitHub) UpdateReleaseArgsForCall(i int) (github.RepositoryRelease, resource.ReleaseOptions) {
 	fake.updateReleaseMutex.RLock()
 	defer fake.updateReleaseMutex.RUnlock()
-	return fake.updateReleaseArgsForCall[i].release
+	return fake.updateReleaseArgsForCall[i].release, fake.updateReleaseArgsForCall[i].options
 }
 
 func (fake *FakeGitHub) UpdateReleaseReturns(result1 *github.RepositoryRelease, result2 error) {
@@ -277,6 +466,38 @@ func (fake *FakeGitHub) UpdateReleaseReturns(result1 *github.RepositoryRelease,
 	}{result1, result2}
 }
 
+func (fake *FakeGitHub) DeleteRelease(release github.RepositoryRelease) error {
+	fake.deleteReleaseMutex.Lock()
+	fake.deleteReleaseArgsForCall = append(fake.deleteReleaseArgsForCall, struct {
+		release github.RepositoryRelease
+	}{release})
+	fake.deleteReleaseMutex.Unlock()
+	if fake.DeleteReleaseStub != nil {
+		return fake.DeleteReleaseStub(release)
+	} else {
+		return fake.deleteReleaseReturns.result1
+	}
+}
+
+func (fake *FakeGitHub) DeleteReleaseCallCount() int {
+	fake.deleteReleaseMutex.RLock()
+	defer fake.deleteReleaseMutex.RUnlock()
+	return len(fake.deleteReleaseArgsForCall)
+}
+
+func (fake *FakeGitHub) DeleteReleaseArgsForCall(i int) github.RepositoryRelease {
+	fake.deleteReleaseMutex.RLock()
+	defer fake.deleteReleaseMutex.RUnlock()
+	return fake.deleteReleaseArgsForCall[i].release
+}
+
+func (fake *FakeGitHub) DeleteReleaseReturns(result1 error) {
+	fake.DeleteReleaseStub = nil
+	fake.deleteReleaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeGitHub) ListReleaseAssets(release github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
 	fake.listReleaseAssetsMutex.Lock()
 	fake.listReleaseAssetsArgsForCall = append(fake.listReleaseAssetsArgsForCall, struct {
@@ -310,16 +531,17 @@ func (fake *FakeGitHub) ListReleaseAssetsReturns(result1 []*github.ReleaseAsset,
 	}{result1, result2}
 }
 
-func (fake *FakeGitHub) UploadReleaseAsset(release github.RepositoryRelease, name string, file *os.File) error {
+func (fake *FakeGitHub) UploadReleaseAsset(release github.RepositoryRelease, name string, content io.Reader, size int64) error {
 	fake.uploadReleaseAssetMutex.Lock()
 	fake.uploadReleaseAssetArgsForCall = append(fake.uploadReleaseAssetArgsForCall, struct {
 		release github.RepositoryRelease
 		name    string
-		file    *os.File
-	}{release, name, file})
+		content io.Reader
+		size    int64
+	}{release, name, content, size})
 	fake.uploadReleaseAssetMutex.Unlock()
 	if fake.UploadReleaseAssetStub != nil {
-		return fake.UploadReleaseAssetStub(release, name, file)
+		return fake.UploadReleaseAssetStub(release, name, content, size)
 	} else {
 		return fake.uploadReleaseAssetReturns.result1
 	}
@@ -331,10 +553,10 @@ func (fake *FakeGitHub) UploadReleaseAssetCallCount() int {
 	return len(fake.uploadReleaseAssetArgsForCall)
 }
 
-func (fake *FakeGitHub) UploadReleaseAssetArgsForCall(i int) (github.RepositoryRelease, string, *os.File) {
+func (fake *FakeGitHub) UploadReleaseAssetArgsForCall(i int) (github.RepositoryRelease, string, io.Reader, int64) {
 	fake.uploadReleaseAssetMutex.RLock()
 	defer fake.uploadReleaseAssetMutex.RUnlock()
-	return fake.uploadReleaseAssetArgsForCall[i].release, fake.uploadReleaseAssetArgsForCall[i].name, fake.uploadReleaseAssetArgsForCall[i].file
+	return fake.uploadReleaseAssetArgsForCall[i].release, fake.uploadReleaseAssetArgsForCall[i].name, fake.uploadReleaseAssetArgsForCall[i].content, fake.uploadReleaseAssetArgsForCall[i].size
 }
 
 func (fake *FakeGitHub) UploadReleaseAssetReturns(result1 error) {
@@ -508,4 +730,261 @@ func (fake *FakeGitHub) GetRefReturns(result1 *github.Reference, result2 error)
 	}{result1, result2}
 }
 
+func (fake *FakeGitHub) GetTag(sha string) (*github.Tag, error) {
+	fake.getTagMutex.Lock()
+	fake.getTagArgsForCall = append(fake.getTagArgsForCall, struct {
+		sha string
+	}{sha})
+	fake.getTagMutex.Unlock()
+	if fake.GetTagStub != nil {
+		return fake.GetTagStub(sha)
+	} else {
+		return fake.getTagReturns.result1, fake.getTagReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) GetTagCallCount() int {
+	fake.getTagMutex.RLock()
+	defer fake.getTagMutex.RUnlock()
+	return len(fake.getTagArgsForCall)
+}
+
+func (fake *FakeGitHub) GetTagArgsForCall(i int) string {
+	fake.getTagMutex.RLock()
+	defer fake.getTagMutex.RUnlock()
+	return fake.getTagArgsForCall[i].sha
+}
+
+func (fake *FakeGitHub) GetTagReturns(result1 *github.Tag, result2 error) {
+	fake.GetTagStub = nil
+	fake.getTagReturns = struct {
+		result1 *github.Tag
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) CreateTag(tag github.Tag) (*github.Tag, error) {
+	fake.createTagMutex.Lock()
+	fake.createTagArgsForCall = append(fake.createTagArgsForCall, struct {
+		tag github.Tag
+	}{tag})
+	fake.createTagMutex.Unlock()
+	if fake.CreateTagStub != nil {
+		return fake.CreateTagStub(tag)
+	} else {
+		return fake.createTagReturns.result1, fake.createTagReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) CreateTagCallCount() int {
+	fake.createTagMutex.RLock()
+	defer fake.createTagMutex.RUnlock()
+	return len(fake.createTagArgsForCall)
+}
+
+func (fake *FakeGitHub) CreateTagArgsForCall(i int) github.Tag {
+	fake.createTagMutex.RLock()
+	defer fake.createTagMutex.RUnlock()
+	return fake.createTagArgsForCall[i].tag
+}
+
+func (fake *FakeGitHub) CreateTagReturns(result1 *github.Tag, result2 error) {
+	fake.CreateTagStub = nil
+	fake.createTagReturns = struct {
+		result1 *github.Tag
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) CreateRef(tag string, sha string) (*github.Reference, error) {
+	fake.createRefMutex.Lock()
+	fake.createRefArgsForCall = append(fake.createRefArgsForCall, struct {
+		tag string
+		sha string
+	}{tag, sha})
+	fake.createRefMutex.Unlock()
+	if fake.CreateRefStub != nil {
+		return fake.CreateRefStub(tag, sha)
+	} else {
+		return fake.createRefReturns.result1, fake.createRefReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) CreateRefCallCount() int {
+	fake.createRefMutex.RLock()
+	defer fake.createRefMutex.RUnlock()
+	return len(fake.createRefArgsForCall)
+}
+
+func (fake *FakeGitHub) CreateRefArgsForCall(i int) (string, string) {
+	fake.createRefMutex.RLock()
+	defer fake.createRefMutex.RUnlock()
+	return fake.createRefArgsForCall[i].tag, fake.createRefArgsForCall[i].sha
+}
+
+func (fake *FakeGitHub) CreateRefReturns(result1 *github.Reference, result2 error) {
+	fake.CreateRefStub = nil
+	fake.createRefReturns = struct {
+		result1 *github.Reference
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) DeleteRef(tag string) error {
+	fake.deleteRefMutex.Lock()
+	fake.deleteRefArgsForCall = append(fake.deleteRefArgsForCall, struct {
+		tag string
+	}{tag})
+	fake.deleteRefMutex.Unlock()
+	if fake.DeleteRefStub != nil {
+		return fake.DeleteRefStub(tag)
+	} else {
+		return fake.deleteRefReturns.result1
+	}
+}
+
+func (fake *FakeGitHub) DeleteRefCallCount() int {
+	fake.deleteRefMutex.RLock()
+	defer fake.deleteRefMutex.RUnlock()
+	return len(fake.deleteRefArgsForCall)
+}
+
+func (fake *FakeGitHub) DeleteRefArgsForCall(i int) string {
+	fake.deleteRefMutex.RLock()
+	defer fake.deleteRefMutex.RUnlock()
+	return fake.deleteRefArgsForCall[i].tag
+}
+
+func (fake *FakeGitHub) DeleteRefReturns(result1 error) {
+	fake.DeleteRefStub = nil
+	fake.deleteRefReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitHub) GetDefaultBranch() (string, error) {
+	fake.getDefaultBranchMutex.Lock()
+	fake.getDefaultBranchArgsForCall = append(fake.getDefaultBranchArgsForCall, struct{}{})
+	fake.getDefaultBranchMutex.Unlock()
+	if fake.GetDefaultBranchStub != nil {
+		return fake.GetDefaultBranchStub()
+	} else {
+		return fake.getDefaultBranchReturns.result1, fake.getDefaultBranchReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) GetDefaultBranchCallCount() int {
+	fake.getDefaultBranchMutex.RLock()
+	defer fake.getDefaultBranchMutex.RUnlock()
+	return len(fake.getDefaultBranchArgsForCall)
+}
+
+func (fake *FakeGitHub) GetDefaultBranchReturns(result1 string, result2 error) {
+	fake.GetDefaultBranchStub = nil
+	fake.getDefaultBranchReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) CompareCommits(base string, head string) (*github.CommitsComparison, error) {
+	fake.compareCommitsMutex.Lock()
+	fake.compareCommitsArgsForCall = append(fake.compareCommitsArgsForCall, struct {
+		base string
+		head string
+	}{base, head})
+	fake.compareCommitsMutex.Unlock()
+	if fake.CompareCommitsStub != nil {
+		return fake.CompareCommitsStub(base, head)
+	} else {
+		return fake.compareCommitsReturns.result1, fake.compareCommitsReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) CompareCommitsCallCount() int {
+	fake.compareCommitsMutex.RLock()
+	defer fake.compareCommitsMutex.RUnlock()
+	return len(fake.compareCommitsArgsForCall)
+}
+
+func (fake *FakeGitHub) CompareCommitsArgsForCall(i int) (string, string) {
+	fake.compareCommitsMutex.RLock()
+	defer fake.compareCommitsMutex.RUnlock()
+	return fake.compareCommitsArgsForCall[i].base, fake.compareCommitsArgsForCall[i].head
+}
+
+func (fake *FakeGitHub) CompareCommitsReturns(result1 *github.CommitsComparison, result2 error) {
+	fake.CompareCommitsStub = nil
+	fake.compareCommitsReturns = struct {
+		result1 *github.CommitsComparison
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) GetIssue(number int) (*github.Issue, error) {
+	fake.getIssueMutex.Lock()
+	fake.getIssueArgsForCall = append(fake.getIssueArgsForCall, struct {
+		number int
+	}{number})
+	fake.getIssueMutex.Unlock()
+	if fake.GetIssueStub != nil {
+		return fake.GetIssueStub(number)
+	} else {
+		return fake.getIssueReturns.result1, fake.getIssueReturns.result2
+	}
+}
+
+func (fake *FakeGitHub) GetIssueCallCount() int {
+	fake.getIssueMutex.RLock()
+	defer fake.getIssueMutex.RUnlock()
+	return len(fake.getIssueArgsForCall)
+}
+
+func (fake *FakeGitHub) GetIssueArgsForCall(i int) int {
+	fake.getIssueMutex.RLock()
+	defer fake.getIssueMutex.RUnlock()
+	return fake.getIssueArgsForCall[i].number
+}
+
+func (fake *FakeGitHub) GetIssueReturns(result1 *github.Issue, result2 error) {
+	fake.GetIssueStub = nil
+	fake.getIssueReturns = struct {
+		result1 *github.Issue
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitHub) CloneRef(ref string, dir string) error {
+	fake.cloneRefMutex.Lock()
+	fake.cloneRefArgsForCall = append(fake.cloneRefArgsForCall, struct {
+		ref string
+		dir string
+	}{ref, dir})
+	fake.cloneRefMutex.Unlock()
+	if fake.CloneRefStub != nil {
+		return fake.CloneRefStub(ref, dir)
+	} else {
+		return fake.cloneRefReturns.result1
+	}
+}
+
+func (fake *FakeGitHub) CloneRefCallCount() int {
+	fake.cloneRefMutex.RLock()
+	defer fake.cloneRefMutex.RUnlock()
+	return len(fake.cloneRefArgsForCall)
+}
+
+func (fake *FakeGitHub) CloneRefArgsForCall(i int) (string, string) {
+	fake.cloneRefMutex.RLock()
+	defer fake.cloneRefMutex.RUnlock()
+	return fake.cloneRefArgsForCall[i].ref, fake.cloneRefArgsForCall[i].dir
+}
+
+func (fake *FakeGitHub) CloneRefReturns(result1 error) {
+	fake.CloneRefStub = nil
+	fake.cloneRefReturns = struct {
+		result1 error
+	}{result1}
+}
+
 var _ resource.GitHub = new(FakeGitHub)
@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// releaseTypeMatches reports whether the release is a release or pre-release
+// that the source is configured for. It does not consider whether it is a
+// draft.
+func releaseTypeMatches(source Source, release *github.RepositoryRelease) bool {
+	// Should we skip this release
+	//   a- prerelease condition dont match our source config
+	//   b- release condition match  prerealse in github since github has true/false to describe release/prerelase
+	return !(source.PreRelease != *release.Prerelease && source.Release == *release.Prerelease)
+}
+
+type releaseFilter struct {
+	authors     []string
+	commitish   *regexp.Regexp
+	bodyExclude *regexp.Regexp
+
+	publishedBefore time.Time
+}
+
+func newReleaseFilter(source Source, now time.Time) (releaseFilter, error) {
+	filter := releaseFilter{authors: source.Authors}
+
+	if source.CommitishFilter != "" {
+		re, err := regexp.Compile(source.CommitishFilter)
+		if err != nil {
+			return releaseFilter{}, err
+		}
+		filter.commitish = re
+	}
+
+	if source.BodyExcludeFilter != "" {
+		re, err := regexp.Compile(source.BodyExcludeFilter)
+		if err != nil {
+			return releaseFilter{}, err
+		}
+		filter.bodyExclude = re
+	}
+
+	if source.MinAge != "" {
+		minAge, err := time.ParseDuration(source.MinAge)
+		if err != nil {
+			return releaseFilter{}, err
+		}
+		filter.publishedBefore = now.Add(-minAge)
+	}
+
+	return filter, nil
+}
+
+func (rf *releaseFilter) matches(release *github.RepositoryRelease) bool {
+	if len(rf.authors) > 0 {
+		if release.Author == nil || release.Author.Login == nil {
+			return false
+		}
+
+		found := false
+		for _, author := range rf.authors {
+			if *release.Author.Login == author {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if rf.commitish != nil {
+		if release.TargetCommitish == nil || !rf.commitish.MatchString(*release.TargetCommitish) {
+			return false
+		}
+	}
+
+	if rf.bodyExclude != nil {
+		if release.Body != nil && rf.bodyExclude.MatchString(*release.Body) {
+			return false
+		}
+	}
+
+	if !rf.publishedBefore.IsZero() {
+		if release.PublishedAt == nil || release.PublishedAt.Time.After(rf.publishedBefore) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -2,11 +2,17 @@ package resource
 
 import (
 	"crypto/tls"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"golang.org/x/oauth2"
 
@@ -19,19 +25,33 @@ import (
 
 type GitHub interface {
 	ListReleases() ([]*github.RepositoryRelease, error)
+	ListAllReleases() ([]*github.RepositoryRelease, error)
 	GetReleaseByTag(tag string) (*github.RepositoryRelease, error)
 	GetRelease(id int) (*github.RepositoryRelease, error)
-	CreateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error)
-	UpdateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error)
+	GetLatestRelease() (*github.RepositoryRelease, error)
+	GetReleaseDetails(id int) (*ReleaseDetails, error)
+	CreateRelease(release github.RepositoryRelease, options ReleaseOptions) (*github.RepositoryRelease, error)
+	UpdateRelease(release github.RepositoryRelease, options ReleaseOptions) (*github.RepositoryRelease, error)
+	DeleteRelease(release github.RepositoryRelease) error
 
 	ListReleaseAssets(release github.RepositoryRelease) ([]*github.ReleaseAsset, error)
-	UploadReleaseAsset(release github.RepositoryRelease, name string, file *os.File) error
+	UploadReleaseAsset(release github.RepositoryRelease, name string, content io.Reader, size int64) error
 	DeleteReleaseAsset(asset github.ReleaseAsset) error
 	DownloadReleaseAsset(asset github.ReleaseAsset) (io.ReadCloser, error)
 
 	GetTarballLink(tag string) (*url.URL, error)
 	GetZipballLink(tag string) (*url.URL, error)
 	GetRef(tag string) (*github.Reference, error)
+	GetTag(sha string) (*github.Tag, error)
+	CreateTag(tag github.Tag) (*github.Tag, error)
+	CreateRef(tag string, sha string) (*github.Reference, error)
+	DeleteRef(tag string) error
+
+	GetDefaultBranch() (string, error)
+	CompareCommits(base string, head string) (*github.CommitsComparison, error)
+	GetIssue(number int) (*github.Issue, error)
+
+	CloneRef(ref string, dir string) error
 }
 
 type GitHubClient struct {
@@ -39,6 +59,9 @@ type GitHubClient struct {
 
 	owner      string
 	repository string
+
+	accessToken string
+	insecure    bool
 }
 
 func NewGitHubClient(source Source) (*GitHubClient, error) {
@@ -89,12 +112,51 @@ func NewGitHubClient(source Source) (*GitHubClient, error) {
 	}
 
 	return &GitHubClient{
-		client:     client,
-		owner:      owner,
-		repository: source.Repository,
+		client:      client,
+		owner:       owner,
+		repository:  source.Repository,
+		accessToken: source.AccessToken,
+		insecure:    source.Insecure,
 	}, nil
 }
 
+// NewGitHubClients constructs a client for each of the source's repositories,
+// keyed by their "owner/repository" name.
+func NewGitHubClients(source Source) (map[string]GitHub, error) {
+	clients := map[string]GitHub{}
+
+	for _, repository := range source.Repositories {
+		client, err := NewGitHubClientForRepository(source, repository)
+		if err != nil {
+			return nil, err
+		}
+
+		clients[repository] = client
+	}
+
+	return clients, nil
+}
+
+// NewGitHubClientForRepository constructs a client for the given
+// "owner/repository", or for the source's own owner and repository if it is
+// empty.
+func NewGitHubClientForRepository(source Source, repository string) (*GitHubClient, error) {
+	if repository == "" {
+		return NewGitHubClient(source)
+	}
+
+	parts := strings.Split(repository, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid repository '%s': expected owner/repository", repository)
+	}
+
+	source.Owner = parts[0]
+	source.User = ""
+	source.Repository = parts[1]
+
+	return NewGitHubClient(source)
+}
+
 func (g *GitHubClient) ListReleases() ([]*github.RepositoryRelease, error) {
 	releases, res, err := g.client.Repositories.ListReleases(context.TODO(), g.owner, g.repository, nil)
 	if err != nil {
@@ -109,6 +171,35 @@ func (g *GitHubClient) ListReleases() ([]*github.RepositoryRelease, error) {
 	return releases, nil
 }
 
+// ListAllReleases follows every page of releases, unlike ListReleases which
+// only returns the most recent ones.
+func (g *GitHubClient) ListAllReleases() ([]*github.RepositoryRelease, error) {
+	allReleases := []*github.RepositoryRelease{}
+
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		releases, res, err := g.client.Repositories.ListReleases(context.TODO(), g.owner, g.repository, opt)
+		if err != nil {
+			return []*github.RepositoryRelease{}, err
+		}
+
+		err = res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		allReleases = append(allReleases, releases...)
+
+		if res.NextPage == 0 {
+			break
+		}
+
+		opt.Page = res.NextPage
+	}
+
+	return allReleases, nil
+}
+
 func (g *GitHubClient) GetReleaseByTag(tag string) (*github.RepositoryRelease, error) {
 	release, res, err := g.client.Repositories.GetReleaseByTag(context.TODO(), g.owner, g.repository, tag)
 	if err != nil {
@@ -137,10 +228,15 @@ func (g *GitHubClient) GetRelease(id int) (*github.RepositoryRelease, error) {
 	return release, nil
 }
 
-func (g *GitHubClient) CreateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error) {
-	createdRelease, res, err := g.client.Repositories.CreateRelease(context.TODO(), g.owner, g.repository, &release)
+// GetLatestRelease returns the release GitHub marks as the repository's
+// latest, or nil if the repository has no releases yet.
+func (g *GitHubClient) GetLatestRelease() (*github.RepositoryRelease, error) {
+	release, res, err := g.client.Repositories.GetLatestRelease(context.TODO(), g.owner, g.repository)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
 	if err != nil {
-		return &github.RepositoryRelease{}, err
+		return nil, err
 	}
 
 	err = res.Body.Close()
@@ -148,15 +244,111 @@ func (g *GitHubClient) CreateRelease(release github.RepositoryRelease) (*github.
 		return nil, err
 	}
 
-	return createdRelease, nil
+	return release, nil
+}
+
+// ReleaseOptions are the settings for creating or updating a release that
+// the GitHub client library does not know about.
+type ReleaseOptions struct {
+	MakeLatest             string
+	DiscussionCategoryName string
+}
+
+type releaseRequest struct {
+	github.RepositoryRelease
+	MakeLatest             string `json:"make_latest,omitempty"`
+	DiscussionCategoryName string `json:"discussion_category_name,omitempty"`
+}
+
+// ReleaseDetails are the parts of a release the GitHub client library does
+// not know about.
+type ReleaseDetails struct {
+	DiscussionURL string           `json:"discussion_url"`
+	Reactions     ReleaseReactions `json:"reactions"`
+	Assets        []AssetDetails   `json:"assets"`
+}
+
+type AssetDetails struct {
+	ID     int    `json:"id"`
+	Digest string `json:"digest"`
 }
 
-func (g *GitHubClient) UpdateRelease(release github.RepositoryRelease) (*github.RepositoryRelease, error) {
+type ReleaseReactions struct {
+	TotalCount int `json:"total_count"`
+	PlusOne    int `json:"+1"`
+	MinusOne   int `json:"-1"`
+	Laugh      int `json:"laugh"`
+	Hooray     int `json:"hooray"`
+	Confused   int `json:"confused"`
+	Heart      int `json:"heart"`
+	Rocket     int `json:"rocket"`
+	Eyes       int `json:"eyes"`
+}
+
+func (g *GitHubClient) GetReleaseDetails(id int) (*ReleaseDetails, error) {
+	u := fmt.Sprintf("repos/%s/%s/releases/%d", g.owner, g.repository, id)
+	req, err := g.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	details := new(ReleaseDetails)
+	res, err := g.client.Do(context.TODO(), req, details)
+	if err != nil {
+		return nil, err
+	}
+
+	err = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+func (g *GitHubClient) CreateRelease(release github.RepositoryRelease, options ReleaseOptions) (*github.RepositoryRelease, error) {
+	u := fmt.Sprintf("repos/%s/%s/releases", g.owner, g.repository)
+	return g.sendRelease("POST", u, newReleaseRequest(release, options))
+}
+
+func (g *GitHubClient) UpdateRelease(release github.RepositoryRelease, options ReleaseOptions) (*github.RepositoryRelease, error) {
 	if release.ID == nil {
 		return nil, errors.New("release did not have an ID: has it been saved yet?")
 	}
 
-	updatedRelease, res, err := g.client.Repositories.EditRelease(context.TODO(), g.owner, g.repository, *release.ID, &release)
+	u := fmt.Sprintf("repos/%s/%s/releases/%d", g.owner, g.repository, *release.ID)
+	return g.sendRelease("PATCH", u, newReleaseRequest(release, options))
+}
+
+func newReleaseRequest(release github.RepositoryRelease, options ReleaseOptions) releaseRequest {
+	return releaseRequest{
+		RepositoryRelease:      release,
+		MakeLatest:             options.MakeLatest,
+		DiscussionCategoryName: options.DiscussionCategoryName,
+	}
+}
+
+func (g *GitHubClient) DeleteRelease(release github.RepositoryRelease) error {
+	if release.ID == nil {
+		return errors.New("release did not have an ID: has it been saved yet?")
+	}
+
+	res, err := g.client.Repositories.DeleteRelease(context.TODO(), g.owner, g.repository, *release.ID)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+func (g *GitHubClient) sendRelease(method string, u string, release releaseRequest) (*github.RepositoryRelease, error) {
+	req, err := g.client.NewRequest(method, u, release)
+	if err != nil {
+		return nil, err
+	}
+
+	sentRelease := new(github.RepositoryRelease)
+	res, err := g.client.Do(context.TODO(), req, sentRelease)
 	if err != nil {
 		return &github.RepositoryRelease{}, err
 	}
@@ -166,7 +358,7 @@ func (g *GitHubClient) UpdateRelease(release github.RepositoryRelease) (*github.
 		return nil, err
 	}
 
-	return updatedRelease, nil
+	return sentRelease, nil
 }
 
 func (g *GitHubClient) ListReleaseAssets(release github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
@@ -183,17 +375,17 @@ func (g *GitHubClient) ListReleaseAssets(release github.RepositoryRelease) ([]*g
 	return assets, nil
 }
 
-func (g *GitHubClient) UploadReleaseAsset(release github.RepositoryRelease, name string, file *os.File) error {
-	_, res, err := g.client.Repositories.UploadReleaseAsset(
-		context.TODO(),
-		g.owner,
-		g.repository,
-		*release.ID,
-		&github.UploadOptions{
-			Name: name,
-		},
-		file,
-	)
+// UploadReleaseAsset uploads the content as the named asset. The content is
+// taken as a reader, rather than a file, so that the upload can be watched.
+func (g *GitHubClient) UploadReleaseAsset(release github.RepositoryRelease, name string, content io.Reader, size int64) error {
+	u := fmt.Sprintf("repos/%s/%s/releases/%d/assets?name=%s", g.owner, g.repository, *release.ID, url.QueryEscape(name))
+
+	req, err := g.client.NewUploadRequest(u, content, size, mime.TypeByExtension(filepath.Ext(name)))
+	if err != nil {
+		return err
+	}
+
+	res, err := g.client.Do(context.TODO(), req, nil)
 	if err != nil {
 		return err
 	}
@@ -222,12 +414,50 @@ func (g *GitHubClient) DownloadReleaseAsset(asset github.ReleaseAsset) (io.ReadC
 			return nil, err
 		}
 
+		err = checkAssetResponse(asset, resp)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+
 		return resp.Body, nil
 	}
 
 	return res, err
 }
 
+// checkAssetResponse makes sure the response from the redirect followed to
+// download the asset is the asset, and not an error page. The asset's content
+// type is only checked against an HTML response, as that is what error pages
+// are served as, while the storage behind the redirect may otherwise describe
+// the asset differently to GitHub.
+func checkAssetResponse(asset github.ReleaseAsset, resp *http.Response) error {
+	name := ""
+	if asset.Name != nil {
+		name = *asset.Name
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download asset `%s`: HTTP status %d", name, resp.StatusCode)
+	}
+
+	if asset.ContentType == nil {
+		return nil
+	}
+
+	served, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || served != "text/html" {
+		return nil
+	}
+
+	expected, _, err := mime.ParseMediaType(*asset.ContentType)
+	if err == nil && expected != served {
+		return fmt.Errorf("failed to download asset `%s`: got %s rather than %s", name, served, expected)
+	}
+
+	return nil
+}
+
 func (g *GitHubClient) GetTarballLink(tag string) (*url.URL, error) {
 	opt := &github.RepositoryContentGetOptions{Ref: tag}
 	u, res, err := g.client.Repositories.GetArchiveLink(context.TODO(), g.owner, g.repository, github.Tarball, opt)
@@ -248,8 +478,26 @@ func (g *GitHubClient) GetZipballLink(tag string) (*url.URL, error) {
 	return u, nil
 }
 
+// GetRef returns the ref of the given tag, or nil if there is no such tag.
 func (g *GitHubClient) GetRef(tag string) (*github.Reference, error) {
 	ref, res, err := g.client.Git.GetRef(context.TODO(), g.owner, g.repository, "tags/"+tag)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return ref, nil
+}
+
+func (g *GitHubClient) CreateRef(tag string, sha string) (*github.Reference, error) {
+	ref, res, err := g.client.Git.CreateRef(context.TODO(), g.owner, g.repository, &github.Reference{
+		Ref: github.String("refs/tags/" + tag),
+		Object: &github.GitObject{
+			SHA: github.String(sha),
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -257,6 +505,135 @@ func (g *GitHubClient) GetRef(tag string) (*github.Reference, error) {
 	return ref, nil
 }
 
+func (g *GitHubClient) DeleteRef(tag string) error {
+	res, err := g.client.Git.DeleteRef(context.TODO(), g.owner, g.repository, "tags/"+tag)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (g *GitHubClient) CreateTag(tag github.Tag) (*github.Tag, error) {
+	createdTag, res, err := g.client.Git.CreateTag(context.TODO(), g.owner, g.repository, &tag)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return createdTag, nil
+}
+
+func (g *GitHubClient) GetTag(sha string) (*github.Tag, error) {
+	tag, res, err := g.client.Git.GetTag(context.TODO(), g.owner, g.repository, sha)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return tag, nil
+}
+
+// GetDefaultBranch returns the name of the branch releases are created from
+// when they are not given a commitish.
+func (g *GitHubClient) GetDefaultBranch() (string, error) {
+	repository, res, err := g.client.Repositories.Get(context.TODO(), g.owner, g.repository)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+
+	if repository.DefaultBranch == nil || *repository.DefaultBranch == "" {
+		return "", fmt.Errorf("no default branch for %s/%s", g.owner, g.repository)
+	}
+
+	return *repository.DefaultBranch, nil
+}
+
+func (g *GitHubClient) CompareCommits(base string, head string) (*github.CommitsComparison, error) {
+	comparison, res, err := g.client.Repositories.CompareCommits(context.TODO(), g.owner, g.repository, base, head)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return comparison, nil
+}
+
+// GetIssue fetches an issue or, since GitHub treats them as issues too, a pull
+// request along with its labels.
+func (g *GitHubClient) GetIssue(number int) (*github.Issue, error) {
+	issue, res, err := g.client.Issues.Get(context.TODO(), g.owner, g.repository, number)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return issue, nil
+}
+
+// CloneRef fetches just the commit at the ref into a new git repository in
+// dir and checks it out. A ref under refs/tags/ is kept as a tag. The clone
+// URL comes from the API, so GitHub Enterprise hosts are cloned from too.
+func (g *GitHubClient) CloneRef(ref string, dir string) error {
+	repository, res, err := g.client.Repositories.Get(context.TODO(), g.owner, g.repository)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	if repository.CloneURL == nil || *repository.CloneURL == "" {
+		return fmt.Errorf("no clone url for %s/%s", g.owner, g.repository)
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	refspec := ref
+	if strings.HasPrefix(ref, "refs/tags/") {
+		refspec = "+" + ref + ":" + ref
+	}
+
+	// the configuration is passed through the environment rather than on the
+	// command line, where the token could be seen in the process list, or in
+	// the remote's URL, where it would be left behind in .git/config
+	config := map[string]string{}
+	if g.accessToken != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + g.accessToken))
+		config["http.extraHeader"] = "Authorization: Basic " + credentials
+	}
+	if g.insecure {
+		config["http.sslVerify"] = "false"
+	}
+
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(config)))
+	i := 0
+	for key, value := range config {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, key), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, value))
+		i++
+	}
+
+	commands := [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", *repository.CloneURL},
+		{"fetch", "-q", "--depth", "1", "--no-tags", "origin", refspec},
+		{"checkout", "-q", "--detach", "FETCH_HEAD"},
+	}
+
+	for _, args := range commands {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = env
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to clone %s at %s: %s: %s", *repository.CloneURL, ref, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	return nil
+}
+
 func oauthClient(ctx context.Context, source Source) (*http.Client, error) {
 	ts := oauth2.StaticTokenSource(&oauth2.Token{
 		AccessToken: source.AccessToken,
@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/google/go-github/github"
 )
@@ -16,12 +18,18 @@ import (
 type InCommand struct {
 	github GitHub
 	writer io.Writer
+	now    func() time.Time
 }
 
 func NewInCommand(github GitHub, writer io.Writer) *InCommand {
+	return NewInCommandWithClock(github, writer, time.Now)
+}
+
+func NewInCommandWithClock(github GitHub, writer io.Writer, now func() time.Time) *InCommand {
 	return &InCommand{
 		github: github,
 		writer: writer,
+		now:    now,
 	}
 }
 
@@ -31,14 +39,35 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 		return InResponse{}, err
 	}
 
+	switch request.Params.ArchiveFormat {
+	case "", "tarball", "zipball":
+	default:
+		return InResponse{}, fmt.Errorf("invalid archive_format '%s': expected tarball or zipball", request.Params.ArchiveFormat)
+	}
+
+	if request.Params.IncludeSource && request.Params.IncludeSourceGit {
+		return InResponse{}, errors.New("include_source and include_source_git cannot both be set")
+	}
+
 	var foundRelease *github.RepositoryRelease
 	var commitSHA string
+	var annotatedTag *github.Tag
+	var parsedVersion string
 
-	if request.Version.Tag != "" {
-		foundRelease, err = c.github.GetReleaseByTag(request.Version.Tag)
-	} else {
+	if request.Version == nil {
+		request.Version = &Version{}
+	}
+
+	if request.Source.GitHubLatest && *request.Version == (Version{}) {
+		foundRelease, err = c.github.GetLatestRelease()
+		if err == nil && foundRelease == nil {
+			err = errors.New("the repository has no latest release")
+		}
+	} else if request.Version.ID != "" {
 		id, _ := strconv.Atoi(request.Version.ID)
 		foundRelease, err = c.github.GetRelease(id)
+	} else {
+		foundRelease, err = c.github.GetReleaseByTag(request.Version.Tag)
 	}
 	if err != nil {
 		return InResponse{}, err
@@ -59,16 +88,23 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 		if err != nil {
 			return InResponse{}, err
 		}
-		version := versionParser.parse(*foundRelease.TagName)
+		parsedVersion = versionParser.parse(*foundRelease.TagName)
 		versionPath := filepath.Join(destDir, "version")
-		err = ioutil.WriteFile(versionPath, []byte(version), 0644)
+		err = ioutil.WriteFile(versionPath, []byte(parsedVersion), 0644)
 		if err != nil {
 			return InResponse{}, err
 		}
 
+		if components, ok := parseVersionComponents(parsedVersion); ok {
+			err = writeVersionComponentFiles(destDir, components)
+			if err != nil {
+				return InResponse{}, err
+			}
+		}
+
 		if foundRelease.Draft != nil && !*foundRelease.Draft {
 			commitPath := filepath.Join(destDir, "commit_sha")
-			commitSHA, err = c.resolveTagToCommitSHA(*foundRelease.TagName)
+			commitSHA, annotatedTag, err = c.resolveTagToCommitSHA(*foundRelease.TagName)
 			if err != nil {
 				return InResponse{}, err
 			}
@@ -79,6 +115,13 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 					return InResponse{}, err
 				}
 			}
+
+			if annotatedTag != nil {
+				err = c.writeTagFiles(destDir, annotatedTag)
+				if err != nil {
+					return InResponse{}, err
+				}
+			}
 		}
 
 		if foundRelease.Body != nil && *foundRelease.Body != "" {
@@ -92,99 +135,298 @@ func (c *InCommand) Run(destDir string, request InRequest) (InResponse, error) {
 
 	}
 
+	// The release's reactions, discussion and asset digests cost another API
+	// request, so they are only fetched when asked for.
+	details := &ReleaseDetails{}
+	if request.Params.IncludeDetails {
+		details, err = c.github.GetReleaseDetails(*foundRelease.ID)
+		if err != nil {
+			return InResponse{}, err
+		}
+
+		reactions, err := json.Marshal(details.Reactions)
+		if err != nil {
+			return InResponse{}, err
+		}
+
+		err = ioutil.WriteFile(filepath.Join(destDir, "reactions.json"), reactions, 0644)
+		if err != nil {
+			return InResponse{}, err
+		}
+	}
+
 	assets, err := c.github.ListReleaseAssets(*foundRelease)
 	if err != nil {
 		return InResponse{}, err
 	}
 
-	for _, asset := range assets {
-		path := filepath.Join(destDir, *asset.Name)
+	err = writeReleaseDocument(destDir, newReleaseDocument(foundRelease, parsedVersion, commitSHA, assets, details))
+	if err != nil {
+		return InResponse{}, err
+	}
 
-		var matchFound bool
-		if len(request.Params.Globs) == 0 {
-			matchFound = true
-		} else {
-			for _, glob := range request.Params.Globs {
-				matches, err := filepath.Match(glob, *asset.Name)
-				if err != nil {
-					return InResponse{}, err
-				}
+	matcher, err := newAssetMatcher(request.Params)
+	if err != nil {
+		return InResponse{}, err
+	}
 
-				if matches {
-					matchFound = true
-					break
-				}
+	modes, err := newAssetModes(request.Params)
+	if err != nil {
+		return InResponse{}, err
+	}
+
+	selectedAssets := matcher.selectAssets(assets)
+	matcher.report(c.writer)
+
+	err = matcher.unmatched()
+	if err != nil {
+		return InResponse{}, err
+	}
+
+	err = checkAssetNames(selectedAssets, request.Params.SkipDownload)
+	if err != nil {
+		return InResponse{}, err
+	}
+
+	downloads := []transferStats{}
+	for _, asset := range selectedAssets {
+		path := filepath.Join(destDir, *asset.Name)
+
+		if request.Params.SkipDownload {
+			err := c.writeURLFile(path, stringValue(asset.BrowserDownloadURL))
+			if err != nil {
+				return InResponse{}, err
 			}
-		}
 
-		if !matchFound {
 			continue
 		}
 
-		fmt.Fprintf(c.writer, "downloading asset: %s\n", *asset.Name)
-
-		err := c.downloadAsset(asset, path)
+		mode, hasMode, err := assetModeFor(modes, *asset.Name)
 		if err != nil {
 			return InResponse{}, err
 		}
-	}
 
-	if request.Params.IncludeSourceTarball {
-		u, err := c.github.GetTarballLink(request.Version.Tag)
+		fmt.Fprintf(c.writer, "downloading asset: %s\n", *asset.Name)
+
+		stats, err := c.downloadAsset(asset, path)
 		if err != nil {
 			return InResponse{}, err
 		}
-		fmt.Fprintln(c.writer, "downloading source tarball to source.tar.gz")
-		if err := c.downloadFile(u.String(), filepath.Join(destDir, "source.tar.gz")); err != nil {
+
+		downloads = append(downloads, stats)
+
+		err = setAssetFileAttributes(path, asset, mode, hasMode)
+		if err != nil {
 			return InResponse{}, err
 		}
 	}
 
-	if request.Params.IncludeSourceZip {
-		u, err := c.github.GetZipballLink(request.Version.Tag)
+	if request.Params.IncludeSourceTarball || request.Params.IncludeSourceZip || request.Params.IncludeSource || request.Params.IncludeSourceGit {
+		sourceRef, err := archiveRef(foundRelease)
 		if err != nil {
 			return InResponse{}, err
 		}
-		fmt.Fprintln(c.writer, "downloading source zip to source.zip")
-		if err := c.downloadFile(u.String(), filepath.Join(destDir, "source.zip")); err != nil {
+
+		err = ioutil.WriteFile(filepath.Join(destDir, "source_ref"), []byte(sourceRef), 0644)
+		if err != nil {
 			return InResponse{}, err
 		}
+
+		if request.Params.IncludeSourceTarball {
+			u, err := c.github.GetTarballLink(sourceRef)
+			if err != nil {
+				return InResponse{}, err
+			}
+			if request.Params.SkipDownload {
+				if err := c.writeURLFile(filepath.Join(destDir, "source.tar.gz"), u.String()); err != nil {
+					return InResponse{}, err
+				}
+			} else {
+				fmt.Fprintln(c.writer, "downloading source tarball to source.tar.gz")
+				if err := c.downloadFile(u.String(), filepath.Join(destDir, "source.tar.gz")); err != nil {
+					return InResponse{}, err
+				}
+			}
+		}
+
+		if request.Params.IncludeSourceZip {
+			u, err := c.github.GetZipballLink(sourceRef)
+			if err != nil {
+				return InResponse{}, err
+			}
+			if request.Params.SkipDownload {
+				if err := c.writeURLFile(filepath.Join(destDir, "source.zip"), u.String()); err != nil {
+					return InResponse{}, err
+				}
+			} else {
+				fmt.Fprintln(c.writer, "downloading source zip to source.zip")
+				if err := c.downloadFile(u.String(), filepath.Join(destDir, "source.zip")); err != nil {
+					return InResponse{}, err
+				}
+			}
+		}
+
+		if request.Params.IncludeSource && !request.Params.SkipDownload {
+			err = c.fetchSource(destDir, sourceRef, request.Params.ArchiveFormat)
+			if err != nil {
+				return InResponse{}, err
+			}
+		}
+
+		if request.Params.IncludeSourceGit && !request.Params.SkipDownload {
+			gitRef := sourceRef
+			if foundRelease.TagName != nil && sourceRef == *foundRelease.TagName {
+				gitRef = "refs/tags/" + sourceRef
+			}
+
+			fmt.Fprintf(c.writer, "cloning source at %s to source\n", sourceRef)
+
+			err = populateAtomically(filepath.Join(destDir, "source"), func(dir string) error {
+				return c.github.CloneRef(gitRef, dir)
+			})
+			if err != nil {
+				return InResponse{}, err
+			}
+		}
 	}
 
+	version := versionFromRelease(foundRelease, request.Source.DetailedVersions)
+	version.Repository = request.Version.Repository
+
+	metadata := metadataFromRelease(foundRelease, commitSHA, request.Source.TagFilter)
+	if annotatedTag != nil {
+		metadata = append(metadata, tagMetadata(annotatedTag)...)
+	}
+
+	metadata = append(metadata, detailsMetadata(details)...)
+	metadata = append(metadata, transferMetadata("downloaded", downloads)...)
+
 	return InResponse{
-		Version:  versionFromRelease(foundRelease),
-		Metadata: metadataFromRelease(foundRelease, commitSHA),
+		Version:  version,
+		Metadata: metadata,
 	}, nil
 }
 
-func (c *InCommand) downloadAsset(asset *github.ReleaseAsset, destPath string) error {
-	out, err := os.Create(destPath)
-	if err != nil {
-		return err
+// inFileNames are the files in writes next to the assets it downloads.
+var inFileNames = map[string]bool{
+	"tag":                true,
+	"version":            true,
+	"body":               true,
+	"commit_sha":         true,
+	"version_major":      true,
+	"version_minor":      true,
+	"version_patch":      true,
+	"version_prerelease": true,
+	"name":               true,
+	"url":                true,
+	"published_at":       true,
+	"release.json":       true,
+	"assets.json":        true,
+	"reactions.json":     true,
+	"tag_sha":            true,
+	"tagger":             true,
+	"tag_message":        true,
+	"tag_verified":       true,
+	"source_ref":         true,
+	"source":             true,
+	"source.tar.gz":      true,
+	"source.tar.gz.url":  true,
+	"source.zip":         true,
+	"source.zip.url":     true,
+}
+
+// checkAssetNames fails if any of the assets would be written over one of the
+// files in writes itself, or the other way around.
+func checkAssetNames(assets []*github.ReleaseAsset, skipDownload bool) error {
+	for _, asset := range assets {
+		name := *asset.Name
+		if skipDownload {
+			name += ".url"
+		}
+
+		if inFileNames[name] {
+			return fmt.Errorf("asset '%s' would overwrite the '%s' file written by in; leave it out with globs", *asset.Name, name)
+		}
+	}
+
+	return nil
+}
+
+func writeVersionComponentFiles(destDir string, components versionComponents) error {
+	files := map[string]string{
+		"version_major":      components.Major,
+		"version_minor":      components.Minor,
+		"version_patch":      components.Patch,
+		"version_prerelease": components.PreRelease,
+	}
+
+	for name, contents := range files {
+		err := ioutil.WriteFile(filepath.Join(destDir, name), []byte(contents), 0644)
+		if err != nil {
+			return err
+		}
 	}
-	defer out.Close()
 
+	return nil
+}
+
+// writeURLFile writes the URL a file would have been downloaded from next to
+// where it would have been, as <name>.url.
+func (c *InCommand) writeURLFile(path string, url string) error {
+	fmt.Fprintf(c.writer, "skipping download of %s\n", filepath.Base(path))
+
+	return ioutil.WriteFile(path+".url", []byte(url), 0644)
+}
+
+// downloadAsset downloads the asset, only writing it into place once it has
+// been checked to be the size GitHub says the asset is.
+func (c *InCommand) downloadAsset(asset *github.ReleaseAsset, destPath string) (transferStats, error) {
 	content, err := c.github.DownloadReleaseAsset(*asset)
 	if err != nil {
-		return err
+		return transferStats{}, err
 	}
 	defer content.Close()
 
-	_, err = io.Copy(out, content)
+	progress := newProgressReader(content, c.writer, c.now, *asset.Name, int64(intValue(asset.Size)))
+
+	err = writeAtomically(destPath, func(out io.Writer) error {
+		written, err := io.Copy(out, progress)
+		if err != nil {
+			return fmt.Errorf("failed to download asset `%s`: %s", *asset.Name, err)
+		}
+
+		if asset.Size != nil && written != int64(*asset.Size) {
+			return fmt.Errorf("failed to download asset `%s`: got %d bytes rather than %d", *asset.Name, written, *asset.Size)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return err
+		return transferStats{}, err
 	}
 
-	return nil
+	return progress.finish("downloaded"), nil
 }
 
-func (c *InCommand) downloadFile(url, destPath string) error {
-	out, err := os.Create(destPath)
-	if err != nil {
-		return err
+// setAssetFileAttributes gives the file its mode, if it has one, and dates it
+// to when the asset was last updated so that it is the same every time the
+// asset is fetched.
+func setAssetFileAttributes(path string, asset *github.ReleaseAsset, mode os.FileMode, hasMode bool) error {
+	if hasMode {
+		err := os.Chmod(path, mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	if asset.UpdatedAt != nil && !asset.UpdatedAt.Time.IsZero() {
+		return os.Chtimes(path, asset.UpdatedAt.Time, asset.UpdatedAt.Time)
 	}
-	defer out.Close()
 
+	return nil
+}
+
+func (c *InCommand) downloadFile(url, destPath string) error {
 	resp, err := http.Get(url)
 	if err != nil {
 		return err
@@ -195,24 +437,64 @@ func (c *InCommand) downloadFile(url, destPath string) error {
 		return fmt.Errorf("failed to download file `%s`: HTTP status %d", filepath.Base(destPath), resp.StatusCode)
 	}
 
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
+	return writeAtomically(destPath, func(out io.Writer) error {
+		_, err := io.Copy(out, resp.Body)
 		return err
-	}
-
-	return nil
+	})
 }
 
-func (c *InCommand) resolveTagToCommitSHA(tag string) (string, error) {
+// resolveTagToCommitSHA follows the tag's ref to the commit it points to. The
+// first annotated tag object found on the way is returned too, or nil for a
+// lightweight tag.
+func (c *InCommand) resolveTagToCommitSHA(tag string) (string, *github.Tag, error) {
 	reference, err := c.github.GetRef(tag)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	if reference == nil {
+		fmt.Fprintf(c.writer, "could not resolve tag '%s' to commit: tag not found\n", tag)
+		return "", nil, nil
+	}
+
+	object, annotatedTag, err := dereferenceTag(c.github, reference)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if object == nil || object.Type == nil || *object.Type != "commit" {
+		fmt.Fprintf(c.writer, "could not resolve tag '%s' to commit: returned type is not 'commit'\n", tag)
+		return "", annotatedTag, nil
+	}
+
+	return *object.SHA, annotatedTag, nil
+}
+
+func (c *InCommand) writeTagFiles(destDir string, tag *github.Tag) error {
+	files := map[string]string{}
+
+	if tag.SHA != nil {
+		files["tag_sha"] = *tag.SHA
+	}
+
+	if tag.Tagger != nil {
+		files["tagger"] = formatTagger(tag.Tagger)
+	}
+
+	if tag.Message != nil {
+		files["tag_message"] = *tag.Message
 	}
 
-	if *reference.Object.Type != "commit" {
-		fmt.Fprintln(c.writer, "could not resolve tag '%s' to commit: returned type is not 'commit' - only lightweight tags are supported")
-		return "", err
+	if tag.Verification != nil && tag.Verification.Verified != nil {
+		files["tag_verified"] = strconv.FormatBool(*tag.Verification.Verified)
 	}
 
-	return *reference.Object.SHA, err
+	for name, contents := range files {
+		err := ioutil.WriteFile(filepath.Join(destDir, name), []byte(contents), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
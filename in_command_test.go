@@ -1,14 +1,22 @@
 package resource_test
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
+	"testing/iotest"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -40,14 +48,19 @@ var _ = Describe("In Command", func() {
 
 		githubClient = &fakes.FakeGitHub{}
 		githubServer = ghttp.NewServer()
-		command = resource.NewInCommand(githubClient, ioutil.Discard)
+		command = resource.NewInCommandWithClock(githubClient, ioutil.Discard, func() time.Time {
+			return time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+		})
 
 		tmpDir, err = ioutil.TempDir("", "github-release")
 		Ω(err).ShouldNot(HaveOccurred())
 
 		destDir = filepath.Join(tmpDir, "destination")
 
-		githubClient.DownloadReleaseAssetReturns(ioutil.NopCloser(bytes.NewBufferString("some-content")), nil)
+		githubClient.DownloadReleaseAssetStub = func(github.ReleaseAsset) (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewBufferString("some-content")), nil
+		}
+		githubClient.GetReleaseDetailsReturns(&resource.ReleaseDetails{}, nil)
 
 		inRequest = resource.InRequest{}
 	})
@@ -142,7 +155,12 @@ var _ = Describe("In Command", func() {
 						resource.MetadataPair{Name: "name", Value: "release-name", URL: "http://google.com"},
 						resource.MetadataPair{Name: "body", Value: "*markdown*", Markdown: true},
 						resource.MetadataPair{Name: "tag", Value: "v0.35.0"},
+						resource.MetadataPair{Name: "version_major", Value: "0"},
+						resource.MetadataPair{Name: "version_minor", Value: "35"},
+						resource.MetadataPair{Name: "version_patch", Value: "0"},
 						resource.MetadataPair{Name: "commit_sha", Value: "f28085a4a8f744da83411f5e09fd7b1709149eee"},
+						resource.MetadataPair{Name: "downloaded", Value: "example.txt: 12 B in 0s (0 B/s)"},
+						resource.MetadataPair{Name: "downloaded", Value: "example.rtf: 12 B in 0s (0 B/s)"},
 					))
 				})
 
@@ -390,7 +408,13 @@ var _ = Describe("In Command", func() {
 						resource.MetadataPair{Name: "name", Value: "release-name", URL: "http://google.com"},
 						resource.MetadataPair{Name: "body", Value: "*markdown*", Markdown: true},
 						resource.MetadataPair{Name: "tag", Value: "v0.35.0"},
+						resource.MetadataPair{Name: "version_major", Value: "0"},
+						resource.MetadataPair{Name: "version_minor", Value: "35"},
+						resource.MetadataPair{Name: "version_patch", Value: "0"},
 						resource.MetadataPair{Name: "commit_sha", Value: "f28085a4a8f744da83411f5e09fd7b1709149eee"},
+						resource.MetadataPair{Name: "downloaded", Value: "example.txt: 12 B in 0s (0 B/s)"},
+						resource.MetadataPair{Name: "downloaded", Value: "example.rtf: 12 B in 0s (0 B/s)"},
+						resource.MetadataPair{Name: "downloaded", Value: "example.wtf: 12 B in 0s (0 B/s)"},
 					))
 				})
 
@@ -410,6 +434,28 @@ var _ = Describe("In Command", func() {
 				It("returns an error", func() {
 					Ω(inErr).Should(HaveOccurred())
 				})
+
+				It("does not leave a partial file behind", func() {
+					Ω(filepath.Join(destDir, "example.txt")).ShouldNot(BeAnExistingFile())
+				})
+			})
+
+			Context("when a downloaded asset is not the size of the asset", func() {
+				BeforeEach(func() {
+					githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+						{ID: github.Int(0), Name: github.String("example.txt"), Size: github.Int(1024)},
+					}, nil)
+
+					inResponse, inErr = command.Run(destDir, inRequest)
+				})
+
+				It("returns an error", func() {
+					Ω(inErr).Should(MatchError("failed to download asset `example.txt`: got 12 bytes rather than 1024"))
+				})
+
+				It("removes what was downloaded", func() {
+					Ω(filepath.Join(destDir, "example.txt")).ShouldNot(BeAnExistingFile())
+				})
 			})
 
 			Context("when listing release assets fails", func() {
@@ -427,6 +473,1050 @@ var _ = Describe("In Command", func() {
 		})
 	})
 
+	Context("when the release has an annotated tag", func() {
+		buildAnnotatedTagRef := func(tagRef, tagSHA string) *github.Reference {
+			return &github.Reference{
+				Ref: github.String(tagRef),
+				URL: github.String("https://example.com"),
+				Object: &github.GitObject{
+					Type: github.String("tag"),
+					SHA:  github.String(tagSHA),
+					URL:  github.String("https://example.com"),
+				},
+			}
+		}
+
+		buildTag := func(tagSHA, objectType, objectSHA string) *github.Tag {
+			return &github.Tag{
+				Tag:     github.String("v0.35.0"),
+				SHA:     github.String(tagSHA),
+				Message: github.String("the best release"),
+				Tagger: &github.CommitAuthor{
+					Name:  github.String("Some Releaser"),
+					Email: github.String("releaser@example.com"),
+				},
+				Object: &github.GitObject{
+					Type: github.String(objectType),
+					SHA:  github.String(objectSHA),
+				},
+				Verification: &github.SignatureVerification{
+					Verified: github.Bool(true),
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildAnnotatedTagRef("v0.35.0", "a1b2c3"), nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+		})
+
+		Context("which points at a commit", func() {
+			BeforeEach(func() {
+				githubClient.GetTagReturns(buildTag("a1b2c3", "commit", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+				inResponse, inErr = command.Run(destDir, inRequest)
+			})
+
+			It("succeeds", func() {
+				Ω(inErr).ShouldNot(HaveOccurred())
+			})
+
+			It("dereferences the tag object", func() {
+				Ω(githubClient.GetTagCallCount()).Should(Equal(1))
+				Ω(githubClient.GetTagArgsForCall(0)).Should(Equal("a1b2c3"))
+			})
+
+			It("writes the commit and tag files", func() {
+				contents, err := ioutil.ReadFile(path.Join(destDir, "commit_sha"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("f28085a4a8f744da83411f5e09fd7b1709149eee"))
+
+				contents, err = ioutil.ReadFile(path.Join(destDir, "tag_sha"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("a1b2c3"))
+
+				contents, err = ioutil.ReadFile(path.Join(destDir, "tagger"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("Some Releaser <releaser@example.com>"))
+
+				contents, err = ioutil.ReadFile(path.Join(destDir, "tag_message"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("the best release"))
+
+				contents, err = ioutil.ReadFile(path.Join(destDir, "tag_verified"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("true"))
+			})
+
+			It("has some sweet metadata", func() {
+				Ω(inResponse.Metadata).Should(ConsistOf(
+					resource.MetadataPair{Name: "url", Value: "http://google.com"},
+					resource.MetadataPair{Name: "name", Value: "release-name", URL: "http://google.com"},
+					resource.MetadataPair{Name: "body", Value: "*markdown*", Markdown: true},
+					resource.MetadataPair{Name: "tag", Value: "v0.35.0"},
+					resource.MetadataPair{Name: "version_major", Value: "0"},
+					resource.MetadataPair{Name: "version_minor", Value: "35"},
+					resource.MetadataPair{Name: "version_patch", Value: "0"},
+					resource.MetadataPair{Name: "commit_sha", Value: "f28085a4a8f744da83411f5e09fd7b1709149eee"},
+					resource.MetadataPair{Name: "tag_sha", Value: "a1b2c3"},
+					resource.MetadataPair{Name: "tagger", Value: "Some Releaser <releaser@example.com>"},
+					resource.MetadataPair{Name: "tag_message", Value: "the best release"},
+					resource.MetadataPair{Name: "tag_verified", Value: "true"},
+				))
+			})
+		})
+
+		Context("which points at another annotated tag", func() {
+			BeforeEach(func() {
+				githubClient.GetTagStub = func(sha string) (*github.Tag, error) {
+					if sha == "a1b2c3" {
+						return buildTag("a1b2c3", "tag", "d4e5f6"), nil
+					}
+					return buildTag("d4e5f6", "commit", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil
+				}
+				inResponse, inErr = command.Run(destDir, inRequest)
+			})
+
+			It("follows the nested tag objects to the commit", func() {
+				Ω(inErr).ShouldNot(HaveOccurred())
+				Ω(githubClient.GetTagCallCount()).Should(Equal(2))
+
+				contents, err := ioutil.ReadFile(path.Join(destDir, "commit_sha"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("f28085a4a8f744da83411f5e09fd7b1709149eee"))
+
+				contents, err = ioutil.ReadFile(path.Join(destDir, "tag_sha"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("a1b2c3"))
+			})
+		})
+
+		Context("when getting the tag object fails", func() {
+			disaster := errors.New("nope")
+
+			BeforeEach(func() {
+				githubClient.GetTagReturns(nil, disaster)
+				inResponse, inErr = command.Run(destDir, inRequest)
+			})
+
+			It("returns the error", func() {
+				Ω(inErr).Should(Equal(disaster))
+			})
+		})
+	})
+
+	Context("when the version has both a tag and an ID", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			inRequest.Source.DetailedVersions = true
+			inRequest.Version = &resource.Version{Tag: "v0.35.0", ID: "1"}
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("succeeds", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+		})
+
+		It("fetches the release by its ID", func() {
+			Ω(githubClient.GetReleaseCallCount()).Should(Equal(1))
+			Ω(githubClient.GetReleaseArgsForCall(0)).Should(Equal(1))
+			Ω(githubClient.GetReleaseByTagCallCount()).Should(Equal(0))
+		})
+
+		It("returns the detailed version", func() {
+			Ω(inResponse.Version).Should(Equal(resource.Version{Tag: "v0.35.0", ID: "1"}))
+		})
+	})
+
+	Context("when the version names a repository", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0", Repository: "some-fork/concourse"}
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("returns the version with the repository", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+			Ω(inResponse.Version).Should(Equal(resource.Version{Tag: "v0.35.0", Repository: "some-fork/concourse"}))
+		})
+	})
+
+	Context("when following GitHub's latest release without a version", func() {
+		BeforeEach(func() {
+			githubClient.GetLatestReleaseReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			inRequest.Source.GitHubLatest = true
+			inRequest.Version = nil
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("fetches the latest release", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+			Ω(githubClient.GetLatestReleaseCallCount()).Should(Equal(1))
+			Ω(githubClient.GetReleaseByTagCallCount()).Should(Equal(0))
+			Ω(inResponse.Version).Should(Equal(resource.Version{Tag: "v0.35.0"}))
+		})
+
+		It("returns an error if the repository has no releases", func() {
+			githubClient.GetLatestReleaseReturns(nil, nil)
+
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).Should(MatchError("the repository has no latest release"))
+		})
+	})
+
+	Context("when describing the release", func() {
+		BeforeEach(func() {
+			publishedAt := time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+			release := buildRelease(1, "v0.35.0", false)
+			release.Author = &github.User{Login: github.String("some-author")}
+			release.CreatedAt = &github.Timestamp{Time: publishedAt.Add(-time.Hour)}
+			release.PublishedAt = &github.Timestamp{Time: publishedAt}
+			release.TargetCommitish = github.String("master")
+			release.TarballURL = github.String("https://example.com/tarball")
+			release.ZipballURL = github.String("https://example.com/zipball")
+
+			githubClient.GetReleaseByTagReturns(release, nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+				{
+					ID:                 github.Int(7),
+					Name:               github.String("example.txt"),
+					Label:              github.String("Example"),
+					Size:               github.Int(12),
+					ContentType:        github.String("text/plain"),
+					DownloadCount:      github.Int(42),
+					BrowserDownloadURL: github.String("https://example.com/example.txt"),
+					URL:                github.String("https://api.example.com/assets/7"),
+					CreatedAt:          &github.Timestamp{Time: publishedAt},
+					UpdatedAt:          &github.Timestamp{Time: publishedAt},
+				},
+			}, nil)
+
+			githubClient.GetReleaseDetailsReturns(&resource.ReleaseDetails{
+				Assets: []resource.AssetDetails{
+					{ID: 7, Digest: "sha256:e0705e68b0468289858b543f8a57f375a3b4f46391a72f94a28d82d6a3dacaa7"},
+				},
+			}, nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inRequest.Params.Globs = []string{"none-of-them"}
+			inRequest.Params.IncludeDetails = true
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("writes release.json", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(filepath.Join(destDir, "release.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(contents).Should(MatchJSON(`{
+				"schema_version": 1,
+				"id": 1,
+				"tag": "v0.35.0",
+				"version": "0.35.0",
+				"name": "release-name",
+				"body": "*markdown*",
+				"url": "http://google.com",
+				"tarball_url": "https://example.com/tarball",
+				"zipball_url": "https://example.com/zipball",
+				"target_commitish": "master",
+				"commit_sha": "f28085a4a8f744da83411f5e09fd7b1709149eee",
+				"author": "some-author",
+				"created_at": "2018-01-10T11:00:00Z",
+				"published_at": "2018-01-10T12:00:00Z",
+				"draft": false,
+				"prerelease": false,
+				"assets": [{
+					"id": 7,
+					"name": "example.txt",
+					"label": "Example",
+					"size": 12,
+					"content_type": "text/plain",
+					"download_count": 42,
+					"browser_download_url": "https://example.com/example.txt",
+					"api_url": "https://api.example.com/assets/7",
+					"digest": "sha256:e0705e68b0468289858b543f8a57f375a3b4f46391a72f94a28d82d6a3dacaa7",
+					"created_at": "2018-01-10T12:00:00Z",
+					"updated_at": "2018-01-10T12:00:00Z"
+				}]
+			}`))
+		})
+
+		It("writes assets.json with every asset, even those not downloaded", func() {
+			contents, err := ioutil.ReadFile(filepath.Join(destDir, "assets.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			var assets []map[string]interface{}
+			Ω(json.Unmarshal(contents, &assets)).Should(Succeed())
+			Ω(assets).Should(HaveLen(1))
+			Ω(assets[0]["name"]).Should(Equal("example.txt"))
+
+			Ω(filepath.Join(destDir, "example.txt")).ShouldNot(BeAnExistingFile())
+		})
+
+		It("writes the name, url and publish time to their own files", func() {
+			for name, expected := range map[string]string{
+				"name":         "release-name",
+				"url":          "http://google.com",
+				"published_at": "2018-01-10T12:00:00Z",
+			} {
+				contents, err := ioutil.ReadFile(filepath.Join(destDir, name))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal(expected))
+			}
+		})
+	})
+
+	Context("when the release has a semantic version", func() {
+		BeforeEach(func() {
+			release := buildRelease(1, "v1.2.3-rc.1", false)
+			release.Prerelease = github.Bool(true)
+			release.Author = &github.User{Login: github.String("some-author")}
+			release.PublishedAt = &github.Timestamp{Time: time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)}
+			release.Body = github.String(strings.Repeat("a", 1001))
+			release.Assets = []github.ReleaseAsset{
+				{ID: github.Int(1), Name: github.String("one"), Size: github.Int(100)},
+				{ID: github.Int(2), Name: github.String("two"), Size: github.Int(23)},
+			}
+
+			githubClient.GetReleaseByTagReturns(release, nil)
+			githubClient.GetRefReturns(buildTagRef("v1.2.3-rc.1", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			inRequest.Source.PreRelease = true
+			inRequest.Version = &resource.Version{Tag: "v1.2.3-rc.1"}
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("writes each component of the version to a file", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			for name, expected := range map[string]string{
+				"version_major":      "1",
+				"version_minor":      "2",
+				"version_patch":      "3",
+				"version_prerelease": "rc.1",
+			} {
+				contents, err := ioutil.ReadFile(filepath.Join(destDir, name))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal(expected))
+			}
+		})
+
+		It("includes the version, author, publish time and assets in the metadata", func() {
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "version_major", Value: "1"}))
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "version_minor", Value: "2"}))
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "version_patch", Value: "3"}))
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "version_prerelease", Value: "rc.1"}))
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "author", Value: "some-author"}))
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "published_at", Value: "2018-01-10T12:00:00Z"}))
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "assets", Value: "2"}))
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "assets_size", Value: "123"}))
+		})
+
+		It("truncates the body in the metadata but not in the body file", func() {
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{
+				Name:     "body",
+				Value:    strings.Repeat("a", 1000) + "…",
+				Markdown: true,
+			}))
+
+			contents, err := ioutil.ReadFile(filepath.Join(destDir, "body"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(contents)).Should(HaveLen(1001))
+		})
+	})
+
+	Context("when the release's version is not semantic", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "nightly-20180110", false), nil)
+			githubClient.GetRefReturns(buildTagRef("nightly-20180110", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			inRequest.Version = &resource.Version{Tag: "nightly-20180110"}
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("does not write the version components", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			Ω(filepath.Join(destDir, "version_major")).ShouldNot(BeAnExistingFile())
+		})
+	})
+
+	Context("when matching assets", func() {
+		var output *bytes.Buffer
+
+		BeforeEach(func() {
+			output = new(bytes.Buffer)
+			command = resource.NewInCommand(githubClient, output)
+
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+				buildAsset(0, "app-linux-amd64.tgz"),
+				buildAsset(1, "app-darwin-amd64.tgz"),
+				buildAsset(2, "app-windows-amd64.zip"),
+				buildAsset(3, "checksums.txt"),
+			}, nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+		})
+
+		downloaded := func() []string {
+			names := []string{}
+			for i := 0; i < githubClient.DownloadReleaseAssetCallCount(); i++ {
+				names = append(names, *githubClient.DownloadReleaseAssetArgsForCall(i).Name)
+			}
+			return names
+		}
+
+		It("supports brace and ** globs", func() {
+			inRequest.Params.Globs = []string{"app-{linux,darwin}-*.tgz", "**.txt"}
+
+			_, err := command.Run(destDir, inRequest)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(downloaded()).Should(Equal([]string{"app-linux-amd64.tgz", "app-darwin-amd64.tgz", "checksums.txt"}))
+		})
+
+		It("supports a regular expression", func() {
+			inRequest.Params.AssetRegex = `^app-(linux|windows)-`
+
+			_, err := command.Run(destDir, inRequest)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(downloaded()).Should(Equal([]string{"app-linux-amd64.tgz", "app-windows-amd64.zip"}))
+		})
+
+		It("reports what each glob matched", func() {
+			inRequest.Params.Globs = []string{"*.tgz", "*.deb"}
+
+			_, err := command.Run(destDir, inRequest)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(output.String()).Should(ContainSubstring("glob '*.tgz' matched: app-linux-amd64.tgz, app-darwin-amd64.tgz\n"))
+			Ω(output.String()).Should(ContainSubstring("glob '*.deb' matched no assets\n"))
+		})
+
+		It("fails before downloading if asked to and a glob matches nothing", func() {
+			failOnUnmatched := true
+			inRequest.Params.Globs = []string{"*.tgz", "*.deb"}
+			inRequest.Params.FailOnUnmatchedGlobs = &failOnUnmatched
+
+			_, err := command.Run(destDir, inRequest)
+			Ω(err).Should(MatchError("no assets matched glob '*.deb'"))
+
+			Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(0))
+		})
+
+		It("fails by default if the regular expression matches nothing", func() {
+			inRequest.Params.AssetRegex = `\.deb$`
+
+			_, err := command.Run(destDir, inRequest)
+			Ω(err).Should(MatchError(`no assets matched asset_regex '\.deb$'`))
+		})
+
+		It("rejects an invalid regular expression", func() {
+			inRequest.Params.AssetRegex = `(`
+
+			_, err := command.Run(destDir, inRequest)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(HavePrefix("invalid asset_regex '('"))
+		})
+	})
+
+	Context("when an asset has the same name as one of the files in writes", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+				{ID: github.Int(0), Name: github.String("app-linux-amd64")},
+				{ID: github.Int(1), Name: github.String("release.json")},
+			}, nil)
+			githubClient.DownloadReleaseAssetStub = func(github.ReleaseAsset) (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewBufferString("some-content")), nil
+			}
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+		})
+
+		It("fails before downloading anything", func() {
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).Should(MatchError("asset 'release.json' would overwrite the 'release.json' file written by in; leave it out with globs"))
+
+			Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(0))
+		})
+
+		It("checks the .url file written in its place when skipping the download", func() {
+			githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+				{ID: github.Int(0), Name: github.String("source.zip")},
+			}, nil)
+			inRequest.Params.SkipDownload = true
+
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).Should(MatchError("asset 'source.zip' would overwrite the 'source.zip.url' file written by in; leave it out with globs"))
+		})
+
+		It("downloads the rest when it is left out", func() {
+			inRequest.Params.Globs = []string{"app-*"}
+
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(1))
+		})
+	})
+
+	Context("when downloading assets", func() {
+		var updatedAt time.Time
+
+		BeforeEach(func() {
+			updatedAt = time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+				{ID: github.Int(0), Name: github.String("app-linux-amd64"), UpdatedAt: &github.Timestamp{Time: updatedAt}},
+				{ID: github.Int(1), Name: github.String("README.txt"), UpdatedAt: &github.Timestamp{Time: updatedAt}},
+			}, nil)
+			githubClient.DownloadReleaseAssetStub = func(github.ReleaseAsset) (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewBufferString("some-content")), nil
+			}
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inRequest.Params.Executable = []string{"*-linux-*"}
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("makes the assets matching the executable globs executable", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			info, err := os.Stat(filepath.Join(destDir, "app-linux-amd64"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0755)))
+
+			info, err = os.Stat(filepath.Join(destDir, "README.txt"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(info.Mode().Perm() & 0111).Should(BeZero())
+		})
+
+		Context("with modes", func() {
+			BeforeEach(func() {
+				inRequest.Params.Executable = nil
+			})
+
+			It("gives the assets matching each glob its mode", func() {
+				Ω(json.Unmarshal([]byte(`{"modes": {"*-linux-*": "0750", "*.txt": 384}}`), &inRequest.Params)).Should(Succeed())
+
+				inResponse, inErr = command.Run(destDir, inRequest)
+				Ω(inErr).ShouldNot(HaveOccurred())
+
+				info, err := os.Stat(filepath.Join(destDir, "app-linux-amd64"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0750)))
+
+				info, err = os.Stat(filepath.Join(destDir, "README.txt"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0600)))
+			})
+
+			It("rejects modes that are not permissions", func() {
+				err := json.Unmarshal([]byte(`{"modes": {"*.txt": "rwxr-xr-x"}}`), &inRequest.Params)
+				Ω(err).Should(MatchError("invalid file mode 'rwxr-xr-x': expected octal permissions such as 0644"))
+
+				err = json.Unmarshal([]byte(`{"modes": {"*.txt": "4755"}}`), &inRequest.Params)
+				Ω(err).Should(MatchError("invalid file mode 04755: expected octal permissions such as 0644"))
+			})
+
+			It("fails if an asset is given different modes", func() {
+				inRequest.Params.Modes = map[string]resource.FileMode{"app-*": 0700, "*-amd64": 0755}
+
+				inResponse, inErr = command.Run(destDir, inRequest)
+				Ω(inErr).Should(MatchError("asset 'app-linux-amd64' is given mode 0755 by glob '*-amd64' but 0700 by glob 'app-*'"))
+			})
+		})
+
+		It("dates the assets to when they were last updated", func() {
+			for _, name := range []string{"app-linux-amd64", "README.txt"} {
+				info, err := os.Stat(filepath.Join(destDir, name))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(info.ModTime().Equal(updatedAt)).Should(BeTrue())
+			}
+		})
+	})
+
+	Context("when reporting download progress", func() {
+		var output *bytes.Buffer
+
+		BeforeEach(func() {
+			output = new(bytes.Buffer)
+
+			// every reading of the clock is three seconds after the last
+			now := time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+			command = resource.NewInCommandWithClock(githubClient, output, func() time.Time {
+				now = now.Add(3 * time.Second)
+				return now
+			})
+
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+				{ID: github.Int(0), Name: github.String("example.txt"), Size: github.Int(len("some-content"))},
+			}, nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("periodically logs how much has been downloaded", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			Ω(output.String()).Should(ContainSubstring("example.txt: 12 B of 12 B (100%) at 2 B/s\n"))
+			Ω(output.String()).Should(ContainSubstring("downloaded example.txt: 12 B in 9s (1 B/s)\n"))
+		})
+
+		It("reports the size and duration of each download in the metadata", func() {
+			Ω(inResponse.Metadata).Should(ContainElement(
+				resource.MetadataPair{Name: "downloaded", Value: "example.txt: 12 B in 9s (1 B/s)"},
+			))
+		})
+	})
+
+	Context("when a download is cut short", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+			githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{buildAsset(0, "example.txt")}, nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+		})
+
+		expectNothingLeftBehind := func(name string) {
+			Ω(filepath.Join(destDir, name)).ShouldNot(BeAnExistingFile())
+
+			entries, err := ioutil.ReadDir(destDir)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			for _, entry := range entries {
+				Ω(entry.Name()).ShouldNot(HavePrefix("." + name))
+			}
+		}
+
+		It("does not leave a partial asset behind", func() {
+			githubClient.DownloadReleaseAssetStub = func(github.ReleaseAsset) (io.ReadCloser, error) {
+				return ioutil.NopCloser(io.MultiReader(
+					strings.NewReader("some-"),
+					iotest.TimeoutReader(strings.NewReader("content")),
+				)), nil
+			}
+
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).Should(HaveOccurred())
+
+			expectNothingLeftBehind("example.txt")
+		})
+
+		It("does not leave a partial source tarball behind", func() {
+			tarballURL, err := url.Parse(githubServer.URL())
+			Ω(err).ShouldNot(HaveOccurred())
+			githubClient.GetTarballLinkReturns(tarballURL, nil)
+
+			githubServer.AppendHandlers(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", "1024")
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("not all of it"))
+			})
+
+			inRequest.Params.IncludeSourceTarball = true
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).Should(HaveOccurred())
+
+			expectNothingLeftBehind("source.tar.gz")
+		})
+
+		It("writes complete downloads readable by everyone", func() {
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			info, err := os.Stat(filepath.Join(destDir, "example.txt"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0644)))
+
+			expectNothingLeftBehind("example.txt.")
+		})
+	})
+
+	Context("when skipping downloads", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+				{
+					ID:                 github.Int(0),
+					Name:               github.String("example.txt"),
+					BrowserDownloadURL: github.String("https://example.com/example.txt"),
+				},
+				{
+					ID:                 github.Int(1),
+					Name:               github.String("example.rtf"),
+					BrowserDownloadURL: github.String("https://example.com/example.rtf"),
+				},
+			}, nil)
+
+			tarballURL, err := url.Parse("https://example.com/tarball")
+			Ω(err).ShouldNot(HaveOccurred())
+			githubClient.GetTarballLinkReturns(tarballURL, nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inRequest.Params.SkipDownload = true
+			inRequest.Params.Globs = []string{"*.txt"}
+			inRequest.Params.IncludeSourceTarball = true
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("does not download anything", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.DownloadReleaseAssetCallCount()).Should(Equal(0))
+			Ω(filepath.Join(destDir, "example.txt")).ShouldNot(BeAnExistingFile())
+			Ω(filepath.Join(destDir, "source.tar.gz")).ShouldNot(BeAnExistingFile())
+		})
+
+		It("writes the URLs of the matching assets", func() {
+			contents, err := ioutil.ReadFile(filepath.Join(destDir, "example.txt.url"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(contents)).Should(Equal("https://example.com/example.txt"))
+
+			Ω(filepath.Join(destDir, "example.rtf.url")).ShouldNot(BeAnExistingFile())
+
+			contents, err = ioutil.ReadFile(filepath.Join(destDir, "source.tar.gz.url"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(contents)).Should(Equal("https://example.com/tarball"))
+		})
+
+		It("still describes every asset", func() {
+			contents, err := ioutil.ReadFile(filepath.Join(destDir, "assets.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			var assets []map[string]interface{}
+			Ω(json.Unmarshal(contents, &assets)).Should(Succeed())
+			Ω(assets).Should(HaveLen(2))
+		})
+	})
+
+	Context("when fetching the source", func() {
+		var release *github.RepositoryRelease
+
+		tarball := func() []byte {
+			buf := &bytes.Buffer{}
+			gz := gzip.NewWriter(buf)
+			tw := tar.NewWriter(gz)
+
+			Ω(tw.WriteHeader(&tar.Header{Name: "pax_global_header", Typeflag: tar.TypeXGlobalHeader})).Should(Succeed())
+			Ω(tw.WriteHeader(&tar.Header{Name: "repo-abc123/", Typeflag: tar.TypeDir, Mode: 0755})).Should(Succeed())
+			Ω(tw.WriteHeader(&tar.Header{Name: "repo-abc123/bin/", Typeflag: tar.TypeDir, Mode: 0755})).Should(Succeed())
+			Ω(tw.WriteHeader(&tar.Header{Name: "repo-abc123/bin/build", Typeflag: tar.TypeReg, Mode: 0755, Size: 7})).Should(Succeed())
+			_, err := tw.Write([]byte("#!/bin/"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(tw.WriteHeader(&tar.Header{Name: "repo-abc123/build", Typeflag: tar.TypeSymlink, Linkname: "bin/build"})).Should(Succeed())
+
+			Ω(tw.Close()).Should(Succeed())
+			Ω(gz.Close()).Should(Succeed())
+			return buf.Bytes()
+		}
+
+		zipball := func() []byte {
+			buf := &bytes.Buffer{}
+			zw := zip.NewWriter(buf)
+
+			_, err := zw.Create("repo-abc123/")
+			Ω(err).ShouldNot(HaveOccurred())
+			w, err := zw.Create("repo-abc123/README.md")
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = w.Write([]byte("# repo"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(zw.Close()).Should(Succeed())
+			return buf.Bytes()
+		}
+
+		BeforeEach(func() {
+			release = buildRelease(1, "v0.35.0", false)
+			release.TargetCommitish = github.String("release-branch")
+
+			githubClient.GetReleaseByTagReturns(release, nil)
+			githubClient.GetReleaseReturns(release, nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			archiveURL, err := url.Parse(githubServer.URL() + "/archive")
+			Ω(err).ShouldNot(HaveOccurred())
+			githubClient.GetTarballLinkReturns(archiveURL, nil)
+			githubClient.GetZipballLinkReturns(archiveURL, nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inRequest.Params.IncludeSource = true
+		})
+
+		Context("as a tarball", func() {
+			BeforeEach(func() {
+				githubServer.AppendHandlers(ghttp.RespondWith(http.StatusOK, tarball()))
+				inResponse, inErr = command.Run(destDir, inRequest)
+			})
+
+			It("fetches the archive at the tag", func() {
+				Ω(inErr).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.GetTarballLinkCallCount()).Should(Equal(1))
+				Ω(githubClient.GetTarballLinkArgsForCall(0)).Should(Equal("v0.35.0"))
+
+				contents, err := ioutil.ReadFile(filepath.Join(destDir, "source_ref"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("v0.35.0"))
+			})
+
+			It("extracts it into source without its top-level directory", func() {
+				contents, err := ioutil.ReadFile(filepath.Join(destDir, "source", "bin", "build"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("#!/bin/"))
+
+				info, err := os.Stat(filepath.Join(destDir, "source", "bin", "build"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0755)))
+
+				target, err := os.Readlink(filepath.Join(destDir, "source", "build"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(target).Should(Equal("bin/build"))
+
+				Ω(filepath.Join(destDir, "source", "repo-abc123")).ShouldNot(BeAnExistingFile())
+				Ω(filepath.Join(destDir, "source", "pax_global_header")).ShouldNot(BeAnExistingFile())
+			})
+
+			It("does not leave the archive behind", func() {
+				entries, err := ioutil.ReadDir(destDir)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				for _, entry := range entries {
+					Ω(entry.Name()).ShouldNot(HavePrefix(".source-archive"))
+				}
+			})
+		})
+
+		Context("as a zipball", func() {
+			BeforeEach(func() {
+				githubServer.AppendHandlers(ghttp.RespondWith(http.StatusOK, zipball()))
+				inRequest.Params.ArchiveFormat = "zipball"
+				inResponse, inErr = command.Run(destDir, inRequest)
+			})
+
+			It("extracts it into source without its top-level directory", func() {
+				Ω(inErr).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.GetZipballLinkCallCount()).Should(Equal(1))
+				Ω(githubClient.GetZipballLinkArgsForCall(0)).Should(Equal("v0.35.0"))
+
+				contents, err := ioutil.ReadFile(filepath.Join(destDir, "source", "README.md"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("# repo"))
+			})
+		})
+
+		Context("of a draft release", func() {
+			BeforeEach(func() {
+				release.Draft = github.Bool(true)
+
+				githubServer.AppendHandlers(ghttp.RespondWith(http.StatusOK, tarball()))
+				inRequest.Version = &resource.Version{ID: "1"}
+				inRequest.Params.IncludeSourceTarball = true
+				inRequest.Params.IncludeSource = false
+				inResponse, inErr = command.Run(destDir, inRequest)
+			})
+
+			It("fetches the archive at the target commitish", func() {
+				Ω(inErr).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.GetTarballLinkCallCount()).Should(Equal(1))
+				Ω(githubClient.GetTarballLinkArgsForCall(0)).Should(Equal("release-branch"))
+
+				contents, err := ioutil.ReadFile(filepath.Join(destDir, "source_ref"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contents)).Should(Equal("release-branch"))
+			})
+		})
+
+		Context("when the archive is cut short", func() {
+			BeforeEach(func() {
+				archive := tarball()
+				githubServer.AppendHandlers(ghttp.RespondWith(http.StatusOK, archive[:len(archive)/2]))
+				inResponse, inErr = command.Run(destDir, inRequest)
+			})
+
+			It("returns an error without leaving a partial source behind", func() {
+				Ω(inErr).Should(HaveOccurred())
+
+				Ω(filepath.Join(destDir, "source")).ShouldNot(BeAnExistingFile())
+
+				leftovers, err := filepath.Glob(filepath.Join(destDir, ".source*"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(leftovers).Should(BeEmpty())
+			})
+		})
+
+		Context("with an invalid archive format", func() {
+			BeforeEach(func() {
+				inRequest.Params.ArchiveFormat = "rar"
+				inResponse, inErr = command.Run(destDir, inRequest)
+			})
+
+			It("returns an error without fetching anything", func() {
+				Ω(inErr).Should(MatchError("invalid archive_format 'rar': expected tarball or zipball"))
+				Ω(githubClient.GetReleaseByTagCallCount()).Should(Equal(0))
+			})
+		})
+	})
+
+	Context("when cloning the source", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			githubClient.CloneRefStub = func(ref string, dir string) error {
+				return ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# concourse"), 0644)
+			}
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inRequest.Params.IncludeSourceGit = true
+		})
+
+		It("clones the tag into source", func() {
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.CloneRefCallCount()).Should(Equal(1))
+			ref, _ := githubClient.CloneRefArgsForCall(0)
+			Ω(ref).Should(Equal("refs/tags/v0.35.0"))
+
+			contents, err := ioutil.ReadFile(filepath.Join(destDir, "source", "README.md"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(contents)).Should(Equal("# concourse"))
+		})
+
+		It("returns an error if cloning fails, without leaving a partial clone behind", func() {
+			githubClient.CloneRefStub = func(ref string, dir string) error {
+				Ω(os.Mkdir(filepath.Join(dir, ".git"), 0755)).Should(Succeed())
+				return errors.New("no such ref")
+			}
+
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).Should(MatchError("no such ref"))
+
+			Ω(filepath.Join(destDir, "source")).ShouldNot(BeAnExistingFile())
+
+			leftovers, err := filepath.Glob(filepath.Join(destDir, ".source*"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(leftovers).Should(BeEmpty())
+		})
+
+		It("cannot also extract a source archive", func() {
+			inRequest.Params.IncludeSource = true
+
+			inResponse, inErr = command.Run(destDir, inRequest)
+			Ω(inErr).Should(MatchError("include_source and include_source_git cannot both be set"))
+		})
+	})
+
+	Context("when the release has reactions and a discussion", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+			githubClient.GetReleaseDetailsReturns(&resource.ReleaseDetails{
+				DiscussionURL: "https://github.com/concourse/concourse/discussions/2",
+				Reactions: resource.ReleaseReactions{
+					TotalCount: 4,
+					PlusOne:    3,
+					Rocket:     1,
+				},
+			}, nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inRequest.Params.IncludeDetails = true
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("writes the reaction counts", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.GetReleaseDetailsArgsForCall(0)).Should(Equal(1))
+
+			contents, err := ioutil.ReadFile(filepath.Join(destDir, "reactions.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(contents).Should(MatchJSON(`{
+				"total_count": 4,
+				"+1": 3,
+				"-1": 0,
+				"laugh": 0,
+				"hooray": 0,
+				"confused": 0,
+				"heart": 0,
+				"rocket": 1,
+				"eyes": 0
+			}`))
+		})
+
+		It("includes the discussion in the metadata", func() {
+			Ω(inResponse.Metadata).Should(ContainElement(resource.MetadataPair{
+				Name:  "discussion_url",
+				Value: "https://github.com/concourse/concourse/discussions/2",
+				URL:   "https://github.com/concourse/concourse/discussions/2",
+			}))
+		})
+	})
+
+	Context("when getting the release's reactions fails", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+			githubClient.GetReleaseDetailsReturns(nil, errors.New("disaster"))
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inRequest.Params.IncludeDetails = true
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("returns the error", func() {
+			Ω(inErr).Should(MatchError("disaster"))
+		})
+	})
+
+	Context("when the release's details are not asked for", func() {
+		BeforeEach(func() {
+			githubClient.GetReleaseByTagReturns(buildRelease(1, "v0.35.0", false), nil)
+			githubClient.GetRefReturns(buildTagRef("v0.35.0", "f28085a4a8f744da83411f5e09fd7b1709149eee"), nil)
+
+			inRequest.Version = &resource.Version{Tag: "v0.35.0"}
+			inResponse, inErr = command.Run(destDir, inRequest)
+		})
+
+		It("does not spend another request on them", func() {
+			Ω(inErr).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.GetReleaseDetailsCallCount()).Should(Equal(0))
+			Ω(filepath.Join(destDir, "reactions.json")).ShouldNot(BeAnExistingFile())
+		})
+	})
+
 	Context("when no tagged release is present", func() {
 		BeforeEach(func() {
 			githubClient.GetReleaseByTagReturns(nil, nil)
@@ -483,6 +1573,9 @@ var _ = Describe("In Command", func() {
 					resource.MetadataPair{Name: "name", Value: "release-name", URL: "http://google.com"},
 					resource.MetadataPair{Name: "body", Value: "*markdown*", Markdown: true},
 					resource.MetadataPair{Name: "tag", Value: "v0.35.0"},
+					resource.MetadataPair{Name: "version_major", Value: "0"},
+					resource.MetadataPair{Name: "version_minor", Value: "35"},
+					resource.MetadataPair{Name: "version_patch", Value: "0"},
 					resource.MetadataPair{Name: "draft", Value: "true"},
 				))
 			})
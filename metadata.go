@@ -1,8 +1,17 @@
 package resource
 
-import "github.com/google/go-github/github"
+import (
+	"fmt"
+	"strconv"
 
-func metadataFromRelease(release *github.RepositoryRelease, commitSHA string) []MetadataPair {
+	"github.com/google/go-github/github"
+)
+
+// maxBodyMetadataLength is how many characters of the body are shown in the
+// Concourse UI. The full body is still written to the body file by in.
+const maxBodyMetadataLength = 1000
+
+func metadataFromRelease(release *github.RepositoryRelease, commitSHA string, tagFilter string) []MetadataPair {
 	metadata := []MetadataPair{}
 
 	if release.Name != nil {
@@ -28,7 +37,7 @@ func metadataFromRelease(release *github.RepositoryRelease, commitSHA string) []
 	if release.Body != nil {
 		metadata = append(metadata, MetadataPair{
 			Name:     "body",
-			Value:    *release.Body,
+			Value:    truncateBody(*release.Body),
 			Markdown: true,
 		})
 	}
@@ -47,14 +56,52 @@ func metadataFromRelease(release *github.RepositoryRelease, commitSHA string) []
 		})
 	}
 
-	if *release.Draft {
+	if release.TagName != nil {
+		versionParser, err := newVersionParser(tagFilter)
+		if err == nil {
+			if components, ok := parseVersionComponents(versionParser.parse(*release.TagName)); ok {
+				metadata = append(metadata, versionComponentsMetadata(components)...)
+			}
+		}
+	}
+
+	if release.Author != nil && release.Author.Login != nil {
+		metadata = append(metadata, MetadataPair{
+			Name:  "author",
+			Value: *release.Author.Login,
+		})
+	}
+
+	if published := timestampValue(release.PublishedAt); published != "" {
+		metadata = append(metadata, MetadataPair{
+			Name:  "published_at",
+			Value: published,
+		})
+	}
+
+	if len(release.Assets) > 0 {
+		totalSize := 0
+		for _, asset := range release.Assets {
+			totalSize += intValue(asset.Size)
+		}
+
+		metadata = append(metadata, MetadataPair{
+			Name:  "assets",
+			Value: strconv.Itoa(len(release.Assets)),
+		}, MetadataPair{
+			Name:  "assets_size",
+			Value: strconv.Itoa(totalSize),
+		})
+	}
+
+	if release.Draft != nil && *release.Draft {
 		metadata = append(metadata, MetadataPair{
 			Name:  "draft",
 			Value: "true",
 		})
 	}
 
-	if *release.Prerelease {
+	if release.Prerelease != nil && *release.Prerelease {
 		metadata = append(metadata, MetadataPair{
 			Name:  "pre-release",
 			Value: "true",
@@ -62,3 +109,93 @@ func metadataFromRelease(release *github.RepositoryRelease, commitSHA string) []
 	}
 	return metadata
 }
+
+func versionComponentsMetadata(components versionComponents) []MetadataPair {
+	metadata := []MetadataPair{
+		{Name: "version_major", Value: components.Major},
+		{Name: "version_minor", Value: components.Minor},
+		{Name: "version_patch", Value: components.Patch},
+	}
+
+	if components.PreRelease != "" {
+		metadata = append(metadata, MetadataPair{
+			Name:  "version_prerelease",
+			Value: components.PreRelease,
+		})
+	}
+
+	return metadata
+}
+
+func truncateBody(body string) string {
+	runes := []rune(body)
+	if len(runes) <= maxBodyMetadataLength {
+		return body
+	}
+
+	return string(runes[:maxBodyMetadataLength]) + "…"
+}
+
+func tagMetadata(tag *github.Tag) []MetadataPair {
+	metadata := []MetadataPair{}
+
+	if tag.SHA != nil {
+		metadata = append(metadata, MetadataPair{
+			Name:  "tag_sha",
+			Value: *tag.SHA,
+		})
+	}
+
+	if tag.Tagger != nil {
+		metadata = append(metadata, MetadataPair{
+			Name:  "tagger",
+			Value: formatTagger(tag.Tagger),
+		})
+	}
+
+	if tag.Message != nil {
+		metadata = append(metadata, MetadataPair{
+			Name:  "tag_message",
+			Value: *tag.Message,
+		})
+	}
+
+	if tag.Verification != nil && tag.Verification.Verified != nil {
+		metadata = append(metadata, MetadataPair{
+			Name:  "tag_verified",
+			Value: strconv.FormatBool(*tag.Verification.Verified),
+		})
+	}
+
+	return metadata
+}
+
+func detailsMetadata(details *ReleaseDetails) []MetadataPair {
+	metadata := []MetadataPair{}
+
+	if details.DiscussionURL != "" {
+		metadata = append(metadata, MetadataPair{
+			Name:  "discussion_url",
+			Value: details.DiscussionURL,
+			URL:   details.DiscussionURL,
+		})
+	}
+
+	return metadata
+}
+
+func formatTagger(tagger *github.CommitAuthor) string {
+	var name, email string
+	if tagger.Name != nil {
+		name = *tagger.Name
+	}
+	if tagger.Email != nil {
+		email = *tagger.Email
+	}
+
+	if email == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s <%s>", name, email)
+}
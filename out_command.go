@@ -1,12 +1,14 @@
 package resource
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/github"
 )
@@ -14,21 +16,38 @@ import (
 type OutCommand struct {
 	github GitHub
 	writer io.Writer
+	now    func() time.Time
+	sleep  func(time.Duration)
 }
 
 func NewOutCommand(github GitHub, writer io.Writer) *OutCommand {
+	return NewOutCommandWithClock(github, writer, time.Now, time.Sleep)
+}
+
+func NewOutCommandWithClock(github GitHub, writer io.Writer, now func() time.Time, sleep func(time.Duration)) *OutCommand {
 	return &OutCommand{
 		github: github,
 		writer: writer,
+		now:    now,
+		sleep:  sleep,
 	}
 }
 
 func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, error) {
 	params := request.Params
 
-	name, err := c.fileContents(filepath.Join(sourceDir, request.Params.NamePath))
-	if err != nil {
-		return OutResponse{}, err
+	switch params.Action {
+	case "", "publish":
+	case "delete":
+		return c.deleteRelease(sourceDir, request)
+	case "yank":
+		return c.yankRelease(sourceDir, request)
+	default:
+		return OutResponse{}, fmt.Errorf("invalid action '%s': expected publish, delete or yank", params.Action)
+	}
+
+	if params.AssetsOnly {
+		return c.uploadAssetsOnly(sourceDir, request)
 	}
 
 	tag, err := c.fileContents(filepath.Join(sourceDir, request.Params.TagPath))
@@ -38,9 +57,58 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 
 	tag = request.Params.TagPrefix + tag
 
+	targetCommitish := ""
+	if request.Params.CommitishPath != "" {
+		targetCommitish, err = c.fileContents(filepath.Join(sourceDir, request.Params.CommitishPath))
+		if err != nil {
+			return OutResponse{}, err
+		}
+	}
+
+	assetPaths, err := c.matchGlobs(sourceDir, params.Globs)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	var templateData releaseTemplateData
+	if params.NameTemplate != "" || params.BodyTemplate != "" {
+		templateData, err = newReleaseTemplateData(request.Source, tag, targetCommitish, assetPaths, c.now())
+		if err != nil {
+			return OutResponse{}, err
+		}
+	}
+
+	var name string
+	if params.NameTemplate != "" {
+		if params.NamePath != "" {
+			return OutResponse{}, errors.New("only one of name and name_template may be specified")
+		}
+
+		name, err = renderReleaseTemplate("name", params.NameTemplate, templateData)
+		if err != nil {
+			return OutResponse{}, err
+		}
+	} else {
+		name, err = c.fileContents(filepath.Join(sourceDir, request.Params.NamePath))
+		if err != nil {
+			return OutResponse{}, err
+		}
+	}
+
 	var body string
 	bodySpecified := false
-	if request.Params.BodyPath != "" {
+	if params.BodyTemplate != "" {
+		if params.BodyPath != "" {
+			return OutResponse{}, errors.New("only one of body and body_template may be specified")
+		}
+
+		bodySpecified = true
+
+		body, err = renderReleaseTemplate("body", params.BodyTemplate, templateData)
+		if err != nil {
+			return OutResponse{}, err
+		}
+	} else if request.Params.BodyPath != "" {
 		bodySpecified = true
 
 		body, err = c.fileContents(filepath.Join(sourceDir, request.Params.BodyPath))
@@ -49,9 +117,32 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 		}
 	}
 
-	targetCommitish := ""
-	if request.Params.CommitishPath != "" {
-		targetCommitish, err = c.fileContents(filepath.Join(sourceDir, request.Params.CommitishPath))
+	switch params.MakeLatest {
+	case "", "true", "false", "legacy":
+	default:
+		return OutResponse{}, fmt.Errorf("invalid make_latest '%s': expected true, false or legacy", params.MakeLatest)
+	}
+
+	switch params.BodyMode {
+	case "", "replace", "append", "prepend":
+	case "section":
+		if params.BodySection == "" {
+			return OutResponse{}, errors.New("body_mode section requires body_section to be specified")
+		}
+	default:
+		return OutResponse{}, fmt.Errorf("invalid body_mode '%s': expected replace, append, prepend or section", params.BodyMode)
+	}
+
+	var retention *retentionPolicy
+	if params.Retain != nil {
+		retention, err = newRetentionPolicy(*params.Retain, request.Source)
+		if err != nil {
+			return OutResponse{}, err
+		}
+	}
+
+	if params.CreateTag {
+		err = c.createTag(sourceDir, params, tag, targetCommitish)
 		if err != nil {
 			return OutResponse{}, err
 		}
@@ -77,6 +168,27 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 		return OutResponse{}, err
 	}
 
+	if params.GenerateNotes {
+		notes, err := c.generateNotes(sourceDir, request, tag, targetCommitish, existingReleases)
+		if err != nil {
+			return OutResponse{}, err
+		}
+
+		if bodySpecified {
+			body = joinBody(notes, body)
+		} else {
+			body = notes
+		}
+
+		bodySpecified = true
+		release.Body = github.String(body)
+	}
+
+	releaseOptions := ReleaseOptions{
+		MakeLatest:             string(params.MakeLatest),
+		DiscussionCategoryName: params.DiscussionCategoryName,
+	}
+
 	var existingRelease *github.RepositoryRelease
 	for _, e := range existingReleases {
 		if e.TagName != nil && *e.TagName == tag {
@@ -91,18 +203,36 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 			return OutResponse{}, err
 		}
 
-		existingRelease.Name = github.String(name)
-		existingRelease.TargetCommitish = github.String(targetCommitish)
-		existingRelease.Draft = github.Bool(draft)
-		existingRelease.Prerelease = github.Bool(prerelease)
+		// merging into the body only adds to the release, so everything
+		// else about it is left alone, apart from assets being re-uploaded
+		merging := bodySpecified && params.BodyMode != "" && params.BodyMode != "replace"
+
+		if !merging {
+			existingRelease.Name = github.String(name)
+			existingRelease.TargetCommitish = github.String(targetCommitish)
+			existingRelease.Draft = github.Bool(draft)
+			existingRelease.Prerelease = github.Bool(prerelease)
+		}
 
 		if bodySpecified {
-			existingRelease.Body = github.String(body)
-		} else {
-			existingRelease.Body = nil
+			existingBody := ""
+			if existingRelease.Body != nil {
+				existingBody = *existingRelease.Body
+			}
+
+			existingRelease.Body = github.String(mergeBody(params.BodyMode, params.BodySection, existingBody, body))
+		}
+
+		uploading := map[string]bool{}
+		for _, filePath := range assetPaths {
+			uploading[filepath.Base(filePath)] = true
 		}
 
 		for _, asset := range releaseAssets {
+			if merging && !uploading[*asset.Name] {
+				continue
+			}
+
 			fmt.Fprintf(c.writer, "clearing existing asset: %s\n", *asset.Name)
 
 			err := c.github.DeleteReleaseAsset(*asset)
@@ -113,42 +243,174 @@ func (c *OutCommand) Run(sourceDir string, request OutRequest) (OutResponse, err
 
 		fmt.Fprintf(c.writer, "updating release %s\n", name)
 
-		release, err = c.github.UpdateRelease(*existingRelease)
+		release, err = c.github.UpdateRelease(*existingRelease, releaseOptions)
 		if err != nil {
 			return OutResponse{}, err
 		}
 	} else {
+		if bodySpecified {
+			release.Body = github.String(mergeBody(params.BodyMode, params.BodySection, "", body))
+		}
+
 		fmt.Fprintf(c.writer, "creating release %s\n", name)
-		release, err = c.github.CreateRelease(*release)
+		release, err = c.github.CreateRelease(*release, releaseOptions)
 		if err != nil {
 			return OutResponse{}, err
 		}
 	}
 
-	for _, fileGlob := range params.Globs {
-		matches, err := filepath.Glob(filepath.Join(sourceDir, fileGlob))
+	uploads := []transferStats{}
+	for _, filePath := range assetPaths {
+		stats, err := c.upload(release, filePath)
 		if err != nil {
 			return OutResponse{}, err
 		}
 
-		if len(matches) == 0 {
-			return OutResponse{}, fmt.Errorf("could not find file that matches glob '%s'", fileGlob)
+		uploads = append(uploads, stats)
+	}
+
+	if retention != nil {
+		err = c.pruneReleases(retention, release)
+		if err != nil {
+			return OutResponse{}, err
 		}
+	}
 
-		for _, filePath := range matches {
-			err := c.upload(release, filePath)
-			if err != nil {
-				return OutResponse{}, err
-			}
+	err = c.refreshAssets(release, uploads)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	metadata := metadataFromRelease(release, "", request.Source.TagFilter)
+
+	if params.DiscussionCategoryName != "" {
+		details, err := c.github.GetReleaseDetails(*release.ID)
+		if err != nil {
+			return OutResponse{}, err
 		}
+
+		metadata = append(metadata, detailsMetadata(details)...)
 	}
 
+	metadata = append(metadata, transferMetadata("uploaded", uploads)...)
+
 	return OutResponse{
-		Version:  versionFromRelease(release),
-		Metadata: metadataFromRelease(release, ""),
+		Version:  versionFromRelease(release, request.Source.DetailedVersions),
+		Metadata: metadata,
 	}, nil
 }
 
+// refreshAssets lists the release's assets again once any have been uploaded,
+// so that the metadata counts them.
+func (c *OutCommand) refreshAssets(release *github.RepositoryRelease, uploads []transferStats) error {
+	if len(uploads) == 0 {
+		return nil
+	}
+
+	assets, err := c.github.ListReleaseAssets(*release)
+	if err != nil {
+		return err
+	}
+
+	release.Assets = []github.ReleaseAsset{}
+	for _, asset := range assets {
+		release.Assets = append(release.Assets, *asset)
+	}
+
+	return nil
+}
+
+// createTag creates the release's tag at the commit, as an annotated tag if
+// a message is given. An existing tag is only reused if it already points at
+// the same commit.
+func (c *OutCommand) createTag(sourceDir string, params OutParams, tag string, commitSHA string) error {
+	if !commitSHAPattern.MatchString(commitSHA) {
+		return fmt.Errorf("creating tag '%s' requires commitish to be a full commit SHA, got '%s'", tag, commitSHA)
+	}
+
+	reference, err := c.github.GetRef(tag)
+	if err != nil {
+		return err
+	}
+
+	if reference != nil {
+		object, _, err := dereferenceTag(c.github, reference)
+		if err != nil {
+			return err
+		}
+
+		if object == nil || object.SHA == nil || *object.SHA != commitSHA {
+			existingSHA := "an unknown object"
+			if object != nil && object.SHA != nil {
+				existingSHA = *object.SHA
+			}
+
+			return fmt.Errorf("tag '%s' already exists at %s, not at commit %s", tag, existingSHA, commitSHA)
+		}
+
+		fmt.Fprintf(c.writer, "tag %s already exists at commit %s\n", tag, commitSHA)
+		return nil
+	}
+
+	refSHA := commitSHA
+
+	if params.TagMessagePath != "" {
+		message, err := c.fileContents(filepath.Join(sourceDir, params.TagMessagePath))
+		if err != nil {
+			return err
+		}
+
+		annotatedTag := github.Tag{
+			Tag:     github.String(tag),
+			Message: github.String(message),
+			Object: &github.GitObject{
+				Type: github.String("commit"),
+				SHA:  github.String(commitSHA),
+			},
+		}
+
+		if params.TaggerName != "" || params.TaggerEmail != "" {
+			annotatedTag.Tagger = &github.CommitAuthor{
+				Name:  github.String(params.TaggerName),
+				Email: github.String(params.TaggerEmail),
+			}
+		}
+
+		fmt.Fprintf(c.writer, "creating annotated tag %s\n", tag)
+
+		createdTag, err := c.github.CreateTag(annotatedTag)
+		if err != nil {
+			return err
+		}
+
+		refSHA = *createdTag.SHA
+	} else {
+		fmt.Fprintf(c.writer, "creating tag %s\n", tag)
+	}
+
+	_, err = c.github.CreateRef(tag, refSHA)
+	return err
+}
+
+func (c *OutCommand) matchGlobs(sourceDir string, globs []string) ([]string, error) {
+	paths := []string{}
+
+	for _, fileGlob := range globs {
+		matches, err := filepath.Glob(filepath.Join(sourceDir, fileGlob))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("could not find file that matches glob '%s'", fileGlob)
+		}
+
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
+
 func (c *OutCommand) fileContents(path string) (string, error) {
 	contents, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -158,35 +420,29 @@ func (c *OutCommand) fileContents(path string) (string, error) {
 	return strings.TrimSpace(string(contents)), nil
 }
 
-func (c *OutCommand) upload(release *github.RepositoryRelease, filePath string) error {
+func (c *OutCommand) upload(release *github.RepositoryRelease, filePath string) (transferStats, error) {
 	fmt.Fprintf(c.writer, "uploading %s\n", filePath)
 
 	name := filepath.Base(filePath)
 
+	var stats transferStats
 	var retryErr error
 	for i := 0; i < 10; i++ {
-		file, err := os.Open(filePath)
-		if err != nil {
-			return err
-		}
-
-		defer file.Close()
-
-		retryErr = c.github.UploadReleaseAsset(*release, name, file)
+		stats, retryErr = c.uploadFile(release, name, filePath)
 		if retryErr == nil {
 			break
 		}
 
 		assets, err := c.github.ListReleaseAssets(*release)
 		if err != nil {
-			return err
+			return transferStats{}, err
 		}
 
 		for _, asset := range assets {
 			if asset.Name != nil && *asset.Name == name {
 				err = c.github.DeleteReleaseAsset(*asset)
 				if err != nil {
-					return err
+					return transferStats{}, err
 				}
 				break
 			}
@@ -194,8 +450,32 @@ func (c *OutCommand) upload(release *github.RepositoryRelease, filePath string)
 	}
 
 	if retryErr != nil {
-		return retryErr
+		return transferStats{}, retryErr
 	}
 
-	return nil
+	return stats, nil
+}
+
+// uploadFile makes a single attempt at uploading the file as the named asset,
+// logging its progress.
+func (c *OutCommand) uploadFile(release *github.RepositoryRelease, name string, filePath string) (transferStats, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return transferStats{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return transferStats{}, err
+	}
+
+	progress := newProgressReader(file, c.writer, c.now, name, info.Size())
+
+	err = c.github.UploadReleaseAsset(*release, name, progress, info.Size())
+	if err != nil {
+		return transferStats{}, err
+	}
+
+	return progress.finish("uploaded"), nil
 }
@@ -1,13 +1,21 @@
 package resource_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
 
 	"github.com/concourse/github-release-resource"
 	"github.com/concourse/github-release-resource/fakes"
@@ -33,12 +41,14 @@ var _ = Describe("Out Command", func() {
 		var err error
 
 		githubClient = &fakes.FakeGitHub{}
-		command = resource.NewOutCommand(githubClient, ioutil.Discard)
+		command = resource.NewOutCommandWithClock(githubClient, ioutil.Discard, func() time.Time {
+			return time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+		}, func(time.Duration) {})
 
 		sourcesDir, err = ioutil.TempDir("", "github-release")
 		Ω(err).ShouldNot(HaveOccurred())
 
-		githubClient.CreateReleaseStub = func(gh github.RepositoryRelease) (*github.RepositoryRelease, error) {
+		githubClient.CreateReleaseStub = func(gh github.RepositoryRelease, options resource.ReleaseOptions) (*github.RepositoryRelease, error) {
 			createdRel := gh
 			createdRel.ID = github.Int(112)
 			createdRel.HTMLURL = github.String("http://google.com")
@@ -47,7 +57,7 @@ var _ = Describe("Out Command", func() {
 			return &createdRel, nil
 		}
 
-		githubClient.UpdateReleaseStub = func(gh github.RepositoryRelease) (*github.RepositoryRelease, error) {
+		githubClient.UpdateReleaseStub = func(gh github.RepositoryRelease, options resource.ReleaseOptions) (*github.RepositoryRelease, error) {
 			return &gh, nil
 		}
 	})
@@ -144,7 +154,7 @@ var _ = Describe("Out Command", func() {
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Draft).Should(Equal(false))
 			})
@@ -161,7 +171,7 @@ var _ = Describe("Out Command", func() {
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Draft).Should(Equal(true))
 			})
@@ -178,12 +188,106 @@ var _ = Describe("Out Command", func() {
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(updatedRelease.Body).Should(BeNil())
 			})
 		})
 
+		Context("when the existing release has a body", func() {
+			var existingBody string
+
+			BeforeEach(func() {
+				existingBody = "existing notes\n\n<!-- section:tests -->\nold results\n<!-- section:tests -->\n\nfooter"
+
+				githubClient.ListReleasesStub = func() ([]*github.RepositoryRelease, error) {
+					release := existingReleases[1]
+					release.Body = github.String(existingBody)
+					return []*github.RepositoryRelease{&release}, nil
+				}
+			})
+
+			updatedBody := func() string {
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
+
+				updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
+				return *updatedRelease.Body
+			}
+
+			It("keeps the body if none is supplied", func() {
+				request.Params.BodyPath = ""
+
+				Ω(updatedBody()).Should(Equal(existingBody))
+			})
+
+			It("replaces the body by default", func() {
+				Ω(updatedBody()).Should(Equal("this is a great release"))
+			})
+
+			It("appends to the body", func() {
+				request.Params.BodyMode = "append"
+
+				Ω(updatedBody()).Should(Equal(existingBody + "\n\nthis is a great release"))
+			})
+
+			It("prepends to the body", func() {
+				request.Params.BodyMode = "prepend"
+
+				Ω(updatedBody()).Should(Equal("this is a great release\n\n" + existingBody))
+			})
+
+			It("replaces the contents of a section", func() {
+				request.Params.BodyMode = "section"
+				request.Params.BodySection = "tests"
+
+				Ω(updatedBody()).Should(Equal("existing notes\n\n<!-- section:tests -->\nthis is a great release\n<!-- section:tests -->\n\nfooter"))
+			})
+
+			It("adds a section that is not there yet", func() {
+				request.Params.BodyMode = "section"
+				request.Params.BodySection = "deployments"
+
+				Ω(updatedBody()).Should(Equal(existingBody + "\n\n<!-- section:deployments -->\nthis is a great release\n<!-- section:deployments -->"))
+			})
+
+			It("leaves everything but the body alone when merging into it", func() {
+				file(filepath.Join(sourcesDir, "rainbows.txt"), "new rainbows")
+				request.Params.Globs = []string{"rainbows.txt"}
+				request.Params.BodyMode = "append"
+				request.Source.Drafts = true
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
+				Ω(updatedRelease.Name).Should(BeNil())
+				Ω(updatedRelease.TargetCommitish).Should(BeNil())
+				Ω(*updatedRelease.Draft).Should(BeFalse())
+
+				Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(1))
+				Ω(*githubClient.DeleteReleaseAssetArgsForCall(0).Name).Should(Equal("rainbows.txt"))
+			})
+
+			It("requires a section name", func() {
+				request.Params.BodyMode = "section"
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).Should(MatchError("body_mode section requires body_section to be specified"))
+			})
+
+			It("rejects an unknown body mode", func() {
+				request.Params.BodyMode = "merge"
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).Should(MatchError("invalid body_mode 'merge': expected replace, append, prepend or section"))
+
+				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(0))
+			})
+		})
+
 		Context("when a commitish is not supplied", func() {
 			It("updates the existing release", func() {
 				_, err := command.Run(sourcesDir, request)
@@ -191,7 +295,7 @@ var _ = Describe("Out Command", func() {
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Body).Should(Equal("this is a great release"))
 				Ω(updatedRelease.TargetCommitish).Should(Equal(github.String("")))
@@ -211,12 +315,28 @@ var _ = Describe("Out Command", func() {
 
 				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
 
-				updatedRelease := githubClient.UpdateReleaseArgsForCall(0)
+				updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
 				Ω(*updatedRelease.Name).Should(Equal("v0.3.12"))
 				Ω(*updatedRelease.Body).Should(Equal("this is a great release"))
 				Ω(updatedRelease.TargetCommitish).Should(Equal(github.String("1z22f1")))
 			})
 		})
+
+		Context("when make_latest is supplied", func() {
+			BeforeEach(func() {
+				request.Params.MakeLatest = "false"
+			})
+
+			It("passes it on with the updated release", func() {
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
+
+				_, options := githubClient.UpdateReleaseArgsForCall(0)
+				Ω(options.MakeLatest).Should(Equal("false"))
+			})
+		})
 	})
 
 	Context("when the release has not already been created", func() {
@@ -247,7 +367,7 @@ var _ = Describe("Out Command", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(release.TargetCommitish).Should(Equal(github.String("a2f4a3")))
 			})
@@ -259,7 +379,7 @@ var _ = Describe("Out Command", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
 
 				// GitHub treats empty string the same as not suppying the field.
 				Ω(release.TargetCommitish).Should(Equal(github.String("")))
@@ -278,7 +398,7 @@ var _ = Describe("Out Command", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -286,13 +406,28 @@ var _ = Describe("Out Command", func() {
 			})
 		})
 
+		Context("with a body for a section", func() {
+			It("creates the release with the section", func() {
+				file(filepath.Join(sourcesDir, "body"), "all tests passed")
+				request.Params.BodyPath = "body"
+				request.Params.BodyMode = "section"
+				request.Params.BodySection = "tests"
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
+				Ω(*release.Body).Should(Equal("<!-- section:tests -->\nall tests passed\n<!-- section:tests -->"))
+			})
+		})
+
 		Context("without a body", func() {
 			It("works", func() {
 				_, err := command.Run(sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -300,12 +435,79 @@ var _ = Describe("Out Command", func() {
 			})
 		})
 
+		Context("with make_latest", func() {
+			It("passes it on with the new release", func() {
+				request.Params.MakeLatest = "legacy"
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
+				_, options := githubClient.CreateReleaseArgsForCall(0)
+
+				Ω(options.MakeLatest).Should(Equal("legacy"))
+			})
+
+			It("leaves it to GitHub when it is not set", func() {
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				_, options := githubClient.CreateReleaseArgsForCall(0)
+				Ω(options.MakeLatest).Should(Equal(""))
+			})
+
+			It("rejects a value GitHub does not understand", func() {
+				request.Params.MakeLatest = "sometimes"
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).Should(MatchError("invalid make_latest 'sometimes': expected true, false or legacy"))
+
+				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+			})
+
+			It("can be given as a boolean in the pipeline", func() {
+				var params resource.OutParams
+				err := json.Unmarshal([]byte(`{"make_latest": true}`), &params)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(params.MakeLatest).Should(Equal(resource.MakeLatest("true")))
+
+				err = json.Unmarshal([]byte(`{"make_latest": "legacy"}`), &params)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(params.MakeLatest).Should(Equal(resource.MakeLatest("legacy")))
+			})
+		})
+
+		Context("with a discussion category", func() {
+			BeforeEach(func() {
+				request.Params.DiscussionCategoryName = "Announcements"
+
+				githubClient.GetReleaseDetailsReturns(&resource.ReleaseDetails{
+					DiscussionURL: "https://github.com/concourse/concourse/discussions/2",
+				}, nil)
+			})
+
+			It("creates a discussion for the release and links to it", func() {
+				response, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				_, options := githubClient.CreateReleaseArgsForCall(0)
+				Ω(options.DiscussionCategoryName).Should(Equal("Announcements"))
+
+				Ω(githubClient.GetReleaseDetailsArgsForCall(0)).Should(Equal(112))
+				Ω(response.Metadata).Should(ContainElement(resource.MetadataPair{
+					Name:  "discussion_url",
+					Value: "https://github.com/concourse/concourse/discussions/2",
+					URL:   "https://github.com/concourse/concourse/discussions/2",
+				}))
+			})
+		})
+
 		It("always defaults to non-draft mode", func() {
 			_, err := command.Run(sourcesDir, request)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-			release := githubClient.CreateReleaseArgsForCall(0)
+			release, _ := githubClient.CreateReleaseArgsForCall(0)
 
 			Ω(*release.Draft).Should(Equal(false))
 		})
@@ -323,7 +525,7 @@ var _ = Describe("Out Command", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -341,6 +543,9 @@ var _ = Describe("Out Command", func() {
 					resource.MetadataPair{Name: "name", Value: "release-name", URL: "http://google.com"},
 					resource.MetadataPair{Name: "body", Value: "*markdown*", Markdown: true},
 					resource.MetadataPair{Name: "tag", Value: "0.3.12"},
+					resource.MetadataPair{Name: "version_major", Value: "0"},
+					resource.MetadataPair{Name: "version_minor", Value: "3"},
+					resource.MetadataPair{Name: "version_patch", Value: "12"},
 					resource.MetadataPair{Name: "pre-release", Value: "true"},
 				))
 			})
@@ -359,7 +564,7 @@ var _ = Describe("Out Command", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -377,6 +582,9 @@ var _ = Describe("Out Command", func() {
 					resource.MetadataPair{Name: "name", Value: "release-name", URL: "http://google.com"},
 					resource.MetadataPair{Name: "body", Value: "*markdown*", Markdown: true},
 					resource.MetadataPair{Name: "tag", Value: "0.3.12"},
+					resource.MetadataPair{Name: "version_major", Value: "0"},
+					resource.MetadataPair{Name: "version_minor", Value: "3"},
+					resource.MetadataPair{Name: "version_patch", Value: "12"},
 				))
 			})
 		})
@@ -393,7 +601,7 @@ var _ = Describe("Out Command", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
 
 				Ω(*release.Name).Should(Equal("v0.3.12"))
 				Ω(*release.TagName).Should(Equal("0.3.12"))
@@ -411,6 +619,9 @@ var _ = Describe("Out Command", func() {
 					resource.MetadataPair{Name: "name", Value: "release-name", URL: "http://google.com"},
 					resource.MetadataPair{Name: "body", Value: "*markdown*", Markdown: true},
 					resource.MetadataPair{Name: "tag", Value: "0.3.12"},
+					resource.MetadataPair{Name: "version_major", Value: "0"},
+					resource.MetadataPair{Name: "version_minor", Value: "3"},
+					resource.MetadataPair{Name: "version_patch", Value: "12"},
 					resource.MetadataPair{Name: "draft", Value: "true"},
 				))
 			})
@@ -446,11 +657,11 @@ var _ = Describe("Out Command", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(1))
-				release, name, file := githubClient.UploadReleaseAssetArgsForCall(0)
+				release, name, _, size := githubClient.UploadReleaseAssetArgsForCall(0)
 
 				Ω(*release.ID).Should(Equal(112))
 				Ω(name).Should(Equal("great-file.tgz"))
-				Ω(file.Name()).Should(Equal(filepath.Join(sourcesDir, "great-file.tgz")))
+				Ω(size).Should(Equal(int64(len("matching"))))
 			})
 
 			It("has some sweet metadata", func() {
@@ -462,9 +673,26 @@ var _ = Describe("Out Command", func() {
 					resource.MetadataPair{Name: "name", Value: "release-name", URL: "http://google.com"},
 					resource.MetadataPair{Name: "body", Value: "*markdown*", Markdown: true},
 					resource.MetadataPair{Name: "tag", Value: "0.3.12"},
+					resource.MetadataPair{Name: "version_major", Value: "0"},
+					resource.MetadataPair{Name: "version_minor", Value: "3"},
+					resource.MetadataPair{Name: "version_patch", Value: "12"},
+					resource.MetadataPair{Name: "uploaded", Value: "great-file.tgz: 0 B in 0s (0 B/s)"},
 				))
 			})
 
+			It("counts the release's assets once they are uploaded", func() {
+				githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+					{ID: github.Int(1), Name: github.String("great-file.tgz"), Size: github.Int(8)},
+					{ID: github.Int(2), Name: github.String("other-file.tgz"), Size: github.Int(1024)},
+				}, nil)
+
+				outResponse, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(outResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "assets", Value: "2"}))
+				Ω(outResponse.Metadata).Should(ContainElement(resource.MetadataPair{Name: "assets_size", Value: "1032"}))
+			})
+
 			It("returns an error if a glob is provided that does not match any files", func() {
 				request.Params.Globs = []string{
 					"*.tgz",
@@ -495,8 +723,8 @@ var _ = Describe("Out Command", func() {
 						},
 					}, nil)
 
-					githubClient.UploadReleaseAssetStub = func(rel github.RepositoryRelease, name string, file *os.File) error {
-						Expect(ioutil.ReadAll(file)).To(Equal([]byte("matching")))
+					githubClient.UploadReleaseAssetStub = func(rel github.RepositoryRelease, name string, content io.Reader, size int64) error {
+						Expect(ioutil.ReadAll(content)).To(Equal([]byte("matching")))
 						Expect(existingAsset).To(BeFalse())
 						existingAsset = true
 						return errors.New("some-error")
@@ -511,10 +739,10 @@ var _ = Describe("Out Command", func() {
 					Ω(githubClient.ListReleaseAssetsCallCount()).Should(Equal(10))
 					Ω(*githubClient.ListReleaseAssetsArgsForCall(9).ID).Should(Equal(112))
 
-					actualRelease, actualName, actualFile := githubClient.UploadReleaseAssetArgsForCall(9)
+					actualRelease, actualName, _, actualSize := githubClient.UploadReleaseAssetArgsForCall(9)
 					Ω(*actualRelease.ID).Should(Equal(112))
 					Ω(actualName).Should(Equal("great-file.tgz"))
-					Ω(actualFile.Name()).Should(Equal(filepath.Join(sourcesDir, "great-file.tgz")))
+					Ω(actualSize).Should(Equal(int64(len("matching"))))
 
 					Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(10))
 					actualAsset := githubClient.DeleteReleaseAssetArgsForCall(8)
@@ -531,7 +759,7 @@ var _ = Describe("Out Command", func() {
 						results <- nil
 						results <- errors.New("6")
 
-						githubClient.UploadReleaseAssetStub = func(github.RepositoryRelease, string, *os.File) error {
+						githubClient.UploadReleaseAssetStub = func(github.RepositoryRelease, string, io.Reader, int64) error {
 							return <-results
 						}
 					})
@@ -541,13 +769,14 @@ var _ = Describe("Out Command", func() {
 						Expect(err).ToNot(HaveOccurred())
 
 						Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(5))
-						Ω(githubClient.ListReleaseAssetsCallCount()).Should(Equal(4))
+						Ω(githubClient.ListReleaseAssetsCallCount()).Should(Equal(5))
 						Ω(*githubClient.ListReleaseAssetsArgsForCall(3).ID).Should(Equal(112))
+						Ω(*githubClient.ListReleaseAssetsArgsForCall(4).ID).Should(Equal(112))
 
-						actualRelease, actualName, actualFile := githubClient.UploadReleaseAssetArgsForCall(4)
+						actualRelease, actualName, _, actualSize := githubClient.UploadReleaseAssetArgsForCall(4)
 						Ω(*actualRelease.ID).Should(Equal(112))
 						Ω(actualName).Should(Equal("great-file.tgz"))
-						Ω(actualFile.Name()).Should(Equal(filepath.Join(sourcesDir, "great-file.tgz")))
+						Ω(actualSize).Should(Equal(int64(len("matching"))))
 
 						Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(4))
 						actualAsset := githubClient.DeleteReleaseAssetArgsForCall(3)
@@ -557,33 +786,1062 @@ var _ = Describe("Out Command", func() {
 			})
 		})
 
-		Context("when the tag_prefix is set", func() {
+		Context("when detailed versions are enabled", func() {
 			BeforeEach(func() {
-				namePath := filepath.Join(sourcesDir, "name")
-				tagPath := filepath.Join(sourcesDir, "tag")
+				request.Source.DetailedVersions = true
+			})
 
-				file(namePath, "v0.3.12")
-				file(tagPath, "0.3.12")
+			It("returns the tag and ID of the created release", func() {
+				outResponse, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(outResponse.Version).Should(Equal(resource.Version{Tag: "0.3.12", ID: "112"}))
+			})
+		})
+
+		Context("when creating the tag", func() {
+			commitSHA := "f28085a4a8f744da83411f5e09fd7b1709149eee"
+
+			BeforeEach(func() {
+				commitishPath := filepath.Join(sourcesDir, "commitish")
+				file(commitishPath, commitSHA)
+				request.Params.CommitishPath = "commitish"
+				request.Params.CreateTag = true
+
+				githubClient.CreateTagStub = func(tag github.Tag) (*github.Tag, error) {
+					tag.SHA = github.String("a1b2c3")
+					return &tag, nil
+				}
+			})
+
+			Context("when the tag does not exist", func() {
+				It("creates a lightweight tag at the commit before the release", func() {
+					githubClient.CreateRefStub = func(string, string) (*github.Reference, error) {
+						Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+						return &github.Reference{}, nil
+					}
+
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(githubClient.GetRefArgsForCall(0)).Should(Equal("0.3.12"))
+					Ω(githubClient.CreateTagCallCount()).Should(Equal(0))
+					Ω(githubClient.CreateRefCallCount()).Should(Equal(1))
+
+					tag, sha := githubClient.CreateRefArgsForCall(0)
+					Ω(tag).Should(Equal("0.3.12"))
+					Ω(sha).Should(Equal(commitSHA))
+
+					Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
+				})
+
+				Context("with a tag message", func() {
+					BeforeEach(func() {
+						file(filepath.Join(sourcesDir, "tag-message"), "the best release\n")
+						request.Params.TagMessagePath = "tag-message"
+						request.Params.TaggerName = "Some Releaser"
+						request.Params.TaggerEmail = "releaser@example.com"
+					})
+
+					It("creates an annotated tag pointing at the commit", func() {
+						_, err := command.Run(sourcesDir, request)
+						Ω(err).ShouldNot(HaveOccurred())
+
+						Ω(githubClient.CreateTagCallCount()).Should(Equal(1))
+						Ω(githubClient.CreateTagArgsForCall(0)).Should(Equal(github.Tag{
+							Tag:     github.String("0.3.12"),
+							Message: github.String("the best release"),
+							Tagger: &github.CommitAuthor{
+								Name:  github.String("Some Releaser"),
+								Email: github.String("releaser@example.com"),
+							},
+							Object: &github.GitObject{
+								Type: github.String("commit"),
+								SHA:  github.String(commitSHA),
+							},
+						}))
+
+						tag, sha := githubClient.CreateRefArgsForCall(0)
+						Ω(tag).Should(Equal("0.3.12"))
+						Ω(sha).Should(Equal("a1b2c3"))
+					})
+				})
+
+				Context("when creating the ref fails", func() {
+					BeforeEach(func() {
+						githubClient.CreateRefReturns(nil, errors.New("disaster"))
+					})
+
+					It("returns the error without creating the release", func() {
+						_, err := command.Run(sourcesDir, request)
+						Ω(err).Should(MatchError("disaster"))
+
+						Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+					})
+				})
+			})
+
+			Context("when the tag already exists at the commit", func() {
+				BeforeEach(func() {
+					githubClient.GetRefReturns(&github.Reference{
+						Object: &github.GitObject{
+							Type: github.String("commit"),
+							SHA:  github.String(commitSHA),
+						},
+					}, nil)
+				})
+
+				It("reuses the tag", func() {
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(githubClient.CreateRefCallCount()).Should(Equal(0))
+					Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
+				})
+			})
+
+			Context("when the tag already exists at a different commit", func() {
+				BeforeEach(func() {
+					githubClient.GetRefReturns(&github.Reference{
+						Object: &github.GitObject{
+							Type: github.String("tag"),
+							SHA:  github.String("a1b2c3"),
+						},
+					}, nil)
+
+					githubClient.GetTagReturns(&github.Tag{
+						SHA: github.String("a1b2c3"),
+						Object: &github.GitObject{
+							Type: github.String("commit"),
+							SHA:  github.String("0123456789012345678901234567890123456789"),
+						},
+					}, nil)
+				})
+
+				It("returns an error without creating the release", func() {
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).Should(MatchError("tag '0.3.12' already exists at 0123456789012345678901234567890123456789, not at commit " + commitSHA))
+
+					Ω(githubClient.CreateRefCallCount()).Should(Equal(0))
+					Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+				})
+			})
+
+			Context("when the commitish is not a commit SHA", func() {
+				BeforeEach(func() {
+					file(filepath.Join(sourcesDir, "commitish"), "master")
+				})
+
+				It("returns an error", func() {
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).Should(MatchError("creating tag '0.3.12' requires commitish to be a full commit SHA, got 'master'"))
+
+					Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+				})
+			})
+		})
+
+		Context("when the name and body are templated", func() {
+			BeforeEach(func() {
+				file(filepath.Join(sourcesDir, "great-file.tgz"), "matching")
+				file(filepath.Join(sourcesDir, "commitish"), "a2f4a3")
 
 				request = resource.OutRequest{
 					Params: resource.OutParams{
-						NamePath:  "name",
-						TagPath:   "tag",
-						TagPrefix: "version-",
+						TagPath:       "tag",
+						TagPrefix:     "v",
+						CommitishPath: "commitish",
+						NameTemplate:  "Release {{.Version}} ({{.Commitish}})",
+						BodyTemplate:  "Built by {{.Env.BUILD_PIPELINE_NAME}}/{{.Env.BUILD_JOB_NAME}}\n{{range .Assets}}\n* {{.Name}} {{.Size}} {{.SHA256}}{{end}}\n",
+						Globs:         []string{"*.tgz"},
 					},
 				}
+
+				os.Setenv("BUILD_PIPELINE_NAME", "some-pipeline")
+				os.Setenv("BUILD_JOB_NAME", "some-job")
 			})
 
-			It("appends the TagPrefix onto the TagName", func() {
+			AfterEach(func() {
+				os.Unsetenv("BUILD_PIPELINE_NAME")
+				os.Unsetenv("BUILD_JOB_NAME")
+			})
+
+			It("renders the name and body", func() {
 				_, err := command.Run(sourcesDir, request)
 				Ω(err).ShouldNot(HaveOccurred())
 
 				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
-				release := githubClient.CreateReleaseArgsForCall(0)
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
 
-				Ω(*release.Name).Should(Equal("v0.3.12"))
-				Ω(*release.TagName).Should(Equal("version-0.3.12"))
+				Ω(*release.Name).Should(Equal("Release 0.3.12 (a2f4a3)"))
+				Ω(*release.Body).Should(Equal("Built by some-pipeline/some-job\n\n* great-file.tgz 8 e0705e68b0468289858b543f8a57f375a3b4f46391a72f94a28d82d6a3dacaa7"))
+			})
+
+			It("gives the templates the time in UTC", func() {
+				command = resource.NewOutCommandWithClock(githubClient, ioutil.Discard, func() time.Time {
+					return time.Date(2018, time.January, 10, 12, 0, 0, 0, time.FixedZone("CET", 60*60))
+				}, func(time.Duration) {})
+
+				request.Params.NameTemplate = `Release {{.Version}} on {{.Timestamp.Format "2006-01-02 15:04 MST"}}`
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
+				Ω(*release.Name).Should(Equal("Release 0.3.12 on 2018-01-10 11:00 UTC"))
+			})
+
+			It("returns an error if both a name file and template are given", func() {
+				request.Params.NamePath = "name"
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).Should(MatchError("only one of name and name_template may be specified"))
+			})
+
+			It("returns an error if both a body file and template are given", func() {
+				request.Params.BodyPath = "body"
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).Should(MatchError("only one of body and body_template may be specified"))
+			})
+
+			It("returns an error if a template is invalid", func() {
+				request.Params.NameTemplate = "{{.Version"
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).Should(HaveOccurred())
+				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
 			})
 		})
+
+		Context("when generating notes", func() {
+			buildCommit := func(message string) github.RepositoryCommit {
+				return github.RepositoryCommit{
+					Commit: &github.Commit{Message: github.String(message)},
+				}
+			}
+
+			buildIssue := func(title, author string, labels ...string) *github.Issue {
+				issue := &github.Issue{
+					Title: github.String(title),
+					User:  &github.User{Login: github.String(author)},
+				}
+				for _, label := range labels {
+					issue.Labels = append(issue.Labels, github.Label{Name: github.String(label)})
+				}
+				return issue
+			}
+
+			BeforeEach(func() {
+				file(filepath.Join(sourcesDir, "tag"), "v0.3.12")
+				request.Source.Release = true
+				request.Params.GenerateNotes = true
+
+				githubClient.ListReleasesReturns([]*github.RepositoryRelease{
+					newRepositoryRelease(1, "v0.3.10"),
+					newRepositoryRelease(2, "v0.3.11"),
+					newPreReleaseRepositoryRelease(3, "v0.3.12-rc.1"),
+					newDraftRepositoryRelease(4, "v0.3.11-draft"),
+					newRepositoryRelease(5, "v0.4.0"),
+				}, nil)
+
+				githubClient.CompareCommitsReturns(&github.CommitsComparison{
+					Commits: []github.RepositoryCommit{
+						buildCommit("Merge pull request #12 from someone/fancy-feature\n\nAdd a fancy feature"),
+						buildCommit("fix the thing (#13)"),
+						buildCommit("tidy up without a pull request"),
+						buildCommit("update docs (#14)"),
+						buildCommit("Merge pull request #12 from someone/fancy-feature"),
+					},
+				}, nil)
+
+				githubClient.GetIssueStub = func(number int) (*github.Issue, error) {
+					switch number {
+					case 12:
+						return buildIssue("Add a fancy feature", "someone", "enhancement"), nil
+					case 13:
+						return buildIssue("Fix the thing", "someone-else", "bug"), nil
+					default:
+						return buildIssue("Update docs", "someone"), nil
+					}
+				}
+			})
+
+			It("compares the default branch against the previous release", func() {
+				githubClient.GetDefaultBranchReturns("main", nil)
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.GetRefArgsForCall(0)).Should(Equal("v0.3.12"))
+
+				Ω(githubClient.CompareCommitsCallCount()).Should(Equal(1))
+				base, head := githubClient.CompareCommitsArgsForCall(0)
+				Ω(base).Should(Equal("v0.3.11"))
+				Ω(head).Should(Equal("main"))
+			})
+
+			It("compares up to the tag if it already exists", func() {
+				githubClient.GetRefReturns(&github.Reference{Ref: github.String("refs/tags/v0.3.12")}, nil)
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.GetDefaultBranchCallCount()).Should(Equal(0))
+
+				_, head := githubClient.CompareCommitsArgsForCall(0)
+				Ω(head).Should(Equal("v0.3.12"))
+			})
+
+			It("looks up each pull request once", func() {
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.GetIssueCallCount()).Should(Equal(3))
+				Ω(githubClient.GetIssueArgsForCall(0)).Should(Equal(12))
+				Ω(githubClient.GetIssueArgsForCall(1)).Should(Equal(13))
+				Ω(githubClient.GetIssueArgsForCall(2)).Should(Equal(14))
+			})
+
+			It("uses the grouped notes as the body", func() {
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
+				Ω(*release.Body).Should(Equal(`## Features
+
+* Add a fancy feature (#12) @someone
+
+## Fixes
+
+* Fix the thing (#13) @someone-else
+
+## Other
+
+* Update docs (#14) @someone`))
+			})
+
+			Context("with a commitish", func() {
+				BeforeEach(func() {
+					file(filepath.Join(sourcesDir, "commitish"), "a2f4a3")
+					request.Params.CommitishPath = "commitish"
+				})
+
+				It("compares up to the commitish", func() {
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					_, head := githubClient.CompareCommitsArgsForCall(0)
+					Ω(head).Should(Equal("a2f4a3"))
+				})
+			})
+
+			Context("with a template and a body", func() {
+				BeforeEach(func() {
+					file(filepath.Join(sourcesDir, "notes.tmpl"), "Changes since {{.PreviousTag}}:{{range .Features}} {{.Title}}{{end}}\n")
+					file(filepath.Join(sourcesDir, "body"), "some handwritten body")
+					request.Params.NotesTemplatePath = "notes.tmpl"
+					request.Params.BodyPath = "body"
+				})
+
+				It("renders the template and prepends it to the body", func() {
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					release, _ := githubClient.CreateReleaseArgsForCall(0)
+					Ω(*release.Body).Should(Equal("Changes since v0.3.11: Add a fancy feature\n\nsome handwritten body"))
+				})
+			})
+
+			Context("with custom labels", func() {
+				BeforeEach(func() {
+					file(filepath.Join(sourcesDir, "notes.tmpl"), "{{range .Fixes}}{{.Number}} {{end}}")
+					request.Params.NotesTemplatePath = "notes.tmpl"
+					request.Params.FixLabels = []string{"enhancement"}
+					request.Params.FeatureLabels = []string{"bug"}
+				})
+
+				It("groups the pull requests by those labels", func() {
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					release, _ := githubClient.CreateReleaseArgsForCall(0)
+					Ω(*release.Body).Should(Equal("12"))
+				})
+			})
+
+			Context("when there is no previous release", func() {
+				BeforeEach(func() {
+					githubClient.ListReleasesReturns([]*github.RepositoryRelease{
+						newRepositoryRelease(5, "v0.4.0"),
+					}, nil)
+				})
+
+				It("does not compare any commits", func() {
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					Ω(githubClient.CompareCommitsCallCount()).Should(Equal(0))
+
+					release, _ := githubClient.CreateReleaseArgsForCall(0)
+					Ω(*release.Body).Should(Equal(""))
+				})
+
+				It("does not put blank lines before the body", func() {
+					file(filepath.Join(sourcesDir, "body"), "*markdown*")
+					request.Params.BodyPath = "body"
+
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).ShouldNot(HaveOccurred())
+
+					release, _ := githubClient.CreateReleaseArgsForCall(0)
+					Ω(*release.Body).Should(Equal("*markdown*"))
+				})
+			})
+
+			Context("when comparing commits fails", func() {
+				BeforeEach(func() {
+					githubClient.CompareCommitsReturns(nil, errors.New("disaster"))
+				})
+
+				It("returns the error", func() {
+					_, err := command.Run(sourcesDir, request)
+					Ω(err).Should(MatchError("disaster"))
+
+					Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+				})
+			})
+		})
+
+		Context("when the tag_prefix is set", func() {
+			BeforeEach(func() {
+				namePath := filepath.Join(sourcesDir, "name")
+				tagPath := filepath.Join(sourcesDir, "tag")
+
+				file(namePath, "v0.3.12")
+				file(tagPath, "0.3.12")
+
+				request = resource.OutRequest{
+					Params: resource.OutParams{
+						NamePath:  "name",
+						TagPath:   "tag",
+						TagPrefix: "version-",
+					},
+				}
+			})
+
+			It("appends the TagPrefix onto the TagName", func() {
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
+				release, _ := githubClient.CreateReleaseArgsForCall(0)
+
+				Ω(*release.Name).Should(Equal("v0.3.12"))
+				Ω(*release.TagName).Should(Equal("version-0.3.12"))
+			})
+		})
+	})
+
+	Context("when deleting a release", func() {
+		BeforeEach(func() {
+			githubClient.ListAllReleasesReturns([]*github.RepositoryRelease{
+				{
+					ID:         github.Int(111),
+					TagName:    github.String("v0.3.11"),
+					Draft:      github.Bool(false),
+					Prerelease: github.Bool(false),
+				},
+				{
+					ID:         github.Int(112),
+					TagName:    github.String("v0.3.12"),
+					Draft:      github.Bool(false),
+					Prerelease: github.Bool(false),
+				},
+			}, nil)
+
+			file(filepath.Join(sourcesDir, "tag"), "0.3.12")
+
+			request = resource.OutRequest{
+				Params: resource.OutParams{
+					Action:    "delete",
+					TagPath:   "tag",
+					TagPrefix: "v",
+				},
+			}
+		})
+
+		It("deletes the release named by the tag file", func() {
+			response, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.DeleteReleaseCallCount()).Should(Equal(1))
+			Ω(*githubClient.DeleteReleaseArgsForCall(0).ID).Should(Equal(112))
+			Ω(githubClient.DeleteRefCallCount()).Should(Equal(0))
+
+			Ω(response.Version).Should(Equal(resource.Version{Tag: "v0.3.12"}))
+		})
+
+		It("deletes the release named by the version", func() {
+			githubClient.GetReleaseReturns(&github.RepositoryRelease{
+				ID:         github.Int(111),
+				TagName:    github.String("v0.3.11"),
+				Draft:      github.Bool(false),
+				Prerelease: github.Bool(false),
+			}, nil)
+
+			request.Params.TagPath = ""
+			request.Params.Version = &resource.Version{Tag: "v0.3.11", ID: "111"}
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.GetReleaseArgsForCall(0)).Should(Equal(111))
+			Ω(githubClient.DeleteReleaseCallCount()).Should(Equal(1))
+			Ω(*githubClient.DeleteReleaseArgsForCall(0).ID).Should(Equal(111))
+		})
+
+		It("returns an error if the release named by the version cannot be fetched", func() {
+			githubClient.GetReleaseReturns(nil, errors.New("disaster"))
+
+			request.Params.TagPath = ""
+			request.Params.Version = &resource.Version{ID: "111"}
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).Should(MatchError("disaster"))
+
+			Ω(githubClient.DeleteReleaseCallCount()).Should(Equal(0))
+		})
+
+		It("deletes the tag too if asked to", func() {
+			request.Params.DeleteTag = true
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.DeleteRefCallCount()).Should(Equal(1))
+			Ω(githubClient.DeleteRefArgsForCall(0)).Should(Equal("v0.3.12"))
+		})
+
+		It("does not publish anything", func() {
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+			Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(0))
+			Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(0))
+		})
+
+		It("finds a release that is only on a later page of releases", func() {
+			server := ghttp.NewServer()
+			defer server.Close()
+
+			nextPage := http.Header{
+				"Link": []string{`<` + server.URL() + `/repos/concourse/concourse/releases?page=2&per_page=100>; rel="next"`},
+			}
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases", "per_page=100"),
+					ghttp.RespondWith(200, `[{ "id": 112, "tag_name": "v0.3.12", "draft": false, "prerelease": false }]`, nextPage),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/repos/concourse/concourse/releases", "page=2&per_page=100"),
+					ghttp.RespondWith(200, `[{ "id": 101, "tag_name": "v0.1.0", "draft": false, "prerelease": false }]`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/repos/concourse/concourse/releases/101"),
+					ghttp.RespondWith(204, ""),
+				),
+			)
+
+			client, err := resource.NewGitHubClient(resource.Source{
+				Owner:        "concourse",
+				Repository:   "concourse",
+				GitHubAPIURL: server.URL(),
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			file(filepath.Join(sourcesDir, "tag"), "0.1.0")
+
+			response, err := resource.NewOutCommand(client, ioutil.Discard).Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(server.ReceivedRequests()).Should(HaveLen(3))
+			Ω(response.Version).Should(Equal(resource.Version{Tag: "v0.1.0"}))
+		})
+
+		It("returns an error if the release does not exist", func() {
+			file(filepath.Join(sourcesDir, "tag"), "0.4.0")
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).Should(MatchError("no release found with tag 'v0.4.0'"))
+
+			Ω(githubClient.DeleteReleaseCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if deleting the release fails", func() {
+			request.Params.DeleteTag = true
+			githubClient.DeleteReleaseReturns(errors.New("disaster"))
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).Should(MatchError("disaster"))
+
+			Ω(githubClient.DeleteRefCallCount()).Should(Equal(0))
+		})
+	})
+
+	Context("when yanking a release", func() {
+		BeforeEach(func() {
+			githubClient.ListAllReleasesReturns([]*github.RepositoryRelease{
+				{
+					ID:         github.Int(112),
+					TagName:    github.String("v0.3.12"),
+					Body:       github.String("this is a great release"),
+					Draft:      github.Bool(false),
+					Prerelease: github.Bool(false),
+				},
+			}, nil)
+
+			request = resource.OutRequest{
+				Params: resource.OutParams{
+					Action:  "yank",
+					Version: &resource.Version{Tag: "v0.3.12"},
+				},
+			}
+		})
+
+		It("turns the release into a pre-release and notes it in the body", func() {
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(1))
+
+			updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
+			Ω(*updatedRelease.Prerelease).Should(BeTrue())
+			Ω(*updatedRelease.Draft).Should(BeFalse())
+			Ω(*updatedRelease.Body).Should(Equal("**This release has been yanked.**\n\nthis is a great release"))
+
+			Ω(githubClient.DeleteReleaseCallCount()).Should(Equal(0))
+			Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(0))
+		})
+
+		It("can turn the release back into a draft, with a reason", func() {
+			file(filepath.Join(sourcesDir, "reason"), "It deletes your data.")
+			request.Params.YankAs = "draft"
+			request.Params.YankReasonPath = "reason"
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
+			Ω(*updatedRelease.Draft).Should(BeTrue())
+			Ω(*updatedRelease.Prerelease).Should(BeFalse())
+			Ω(*updatedRelease.Body).Should(Equal("**This release has been yanked.** It deletes your data.\n\nthis is a great release"))
+		})
+
+		It("does not note it twice", func() {
+			githubClient.ListAllReleasesReturns([]*github.RepositoryRelease{
+				{
+					ID:         github.Int(112),
+					TagName:    github.String("v0.3.12"),
+					Body:       github.String("**This release has been yanked.**\n\nthis is a great release"),
+					Draft:      github.Bool(false),
+					Prerelease: github.Bool(true),
+				},
+			}, nil)
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			updatedRelease, _ := githubClient.UpdateReleaseArgsForCall(0)
+			Ω(*updatedRelease.Body).Should(Equal("**This release has been yanked.**\n\nthis is a great release"))
+		})
+
+		It("rejects an unknown yank_as", func() {
+			request.Params.YankAs = "deleted"
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).Should(MatchError("invalid yank_as 'deleted': expected draft or prerelease"))
+		})
+	})
+
+	Context("when a retention policy is given", func() {
+		var now time.Time
+
+		nightly := func(id int, tag string, age time.Duration) *github.RepositoryRelease {
+			return &github.RepositoryRelease{
+				ID:          github.Int(id),
+				TagName:     github.String(tag),
+				Draft:       github.Bool(false),
+				Prerelease:  github.Bool(false),
+				PublishedAt: &github.Timestamp{Time: now.Add(-age)},
+			}
+		}
+
+		BeforeEach(func() {
+			now = time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+			command = resource.NewOutCommandWithClock(githubClient, ioutil.Discard, func() time.Time { return now }, func(time.Duration) {})
+
+			githubClient.ListAllReleasesReturns([]*github.RepositoryRelease{
+				nightly(4, "v1.0.4", time.Hour),
+				nightly(1, "v1.0.1", 96*time.Hour),
+				nightly(3, "v1.0.3", 24*time.Hour),
+				nightly(2, "v1.0.2", 48*time.Hour),
+				nightly(5, "v2.0.0-final", 72*time.Hour),
+				{
+					ID:      github.Int(6),
+					TagName: github.String("v0.0.1"),
+					Draft:   github.Bool(true),
+				},
+				nightly(112, "v1.0.5", 0),
+			}, nil)
+
+			file(filepath.Join(sourcesDir, "name"), "v1.0.5")
+			file(filepath.Join(sourcesDir, "tag"), "v1.0.5")
+
+			request = resource.OutRequest{
+				Source: resource.Source{Release: true},
+				Params: resource.OutParams{
+					NamePath: "name",
+					TagPath:  "tag",
+					Retain: &resource.RetentionPolicy{
+						Count: 3,
+						Match: `^v1\.`,
+					},
+				},
+			}
+		})
+
+		deletedTags := func() []string {
+			tags := []string{}
+			for i := 0; i < githubClient.DeleteReleaseCallCount(); i++ {
+				tags = append(tags, *githubClient.DeleteReleaseArgsForCall(i).TagName)
+			}
+			return tags
+		}
+
+		It("deletes the oldest matching releases beyond the count after publishing", func() {
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.CreateReleaseCallCount()).Should(Equal(1))
+			Ω(deletedTags()).Should(Equal([]string{"v1.0.1", "v1.0.2"}))
+			Ω(githubClient.DeleteRefCallCount()).Should(Equal(0))
+		})
+
+		It("deletes releases older than the max age", func() {
+			request.Params.Retain = &resource.RetentionPolicy{MaxAge: "36h", Match: `^v1\.`}
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(deletedTags()).Should(Equal([]string{"v1.0.1", "v1.0.2"}))
+		})
+
+		It("deletes the tags too if asked to", func() {
+			request.Params.Retain.DeleteTags = true
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.DeleteRefCallCount()).Should(Equal(2))
+			Ω(githubClient.DeleteRefArgsForCall(0)).Should(Equal("v1.0.1"))
+			Ω(githubClient.DeleteRefArgsForCall(1)).Should(Equal("v1.0.2"))
+		})
+
+		It("only logs what it would delete in a dry run", func() {
+			request.Params.Retain.DryRun = true
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.ListAllReleasesCallCount()).Should(Equal(1))
+			Ω(githubClient.DeleteReleaseCallCount()).Should(Equal(0))
+			Ω(githubClient.DeleteRefCallCount()).Should(Equal(0))
+		})
+
+		It("never deletes the release that was just published", func() {
+			request.Params.Retain = &resource.RetentionPolicy{MaxAge: "1ns"}
+			githubClient.CreateReleaseStub = func(gh github.RepositoryRelease, options resource.ReleaseOptions) (*github.RepositoryRelease, error) {
+				return nightly(112, "v1.0.5", time.Hour), nil
+			}
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(deletedTags()).ShouldNot(ContainElement("v1.0.5"))
+			Ω(deletedTags()).Should(ContainElement("v2.0.0-final"))
+			Ω(deletedTags()).ShouldNot(ContainElement("v0.0.1"))
+		})
+
+		It("rejects a policy without a count or max age before publishing", func() {
+			request.Params.Retain = &resource.RetentionPolicy{Match: "nightly-.*"}
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).Should(MatchError("retain requires a count or a max_age"))
+
+			Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if listing the releases fails", func() {
+			githubClient.ListAllReleasesReturns(nil, errors.New("disaster"))
+
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).Should(MatchError("disaster"))
+		})
+	})
+
+	Context("when reporting upload progress", func() {
+		var output *bytes.Buffer
+
+		BeforeEach(func() {
+			output = new(bytes.Buffer)
+
+			// every reading of the clock is three seconds after the last
+			now := time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC)
+			command = resource.NewOutCommandWithClock(githubClient, output, func() time.Time {
+				now = now.Add(3 * time.Second)
+				return now
+			}, func(time.Duration) {})
+
+			githubClient.UploadReleaseAssetStub = func(_ github.RepositoryRelease, _ string, content io.Reader, _ int64) error {
+				_, err := ioutil.ReadAll(content)
+				return err
+			}
+
+			file(filepath.Join(sourcesDir, "name"), "v0.3.12")
+			file(filepath.Join(sourcesDir, "tag"), "v0.3.12")
+			file(filepath.Join(sourcesDir, "linux-amd64"), "linux binary")
+
+			request = resource.OutRequest{
+				Params: resource.OutParams{
+					NamePath: "name",
+					TagPath:  "tag",
+					Globs:    []string{"linux-amd64"},
+				},
+			}
+		})
+
+		It("periodically logs how much has been uploaded", func() {
+			_, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(output.String()).Should(ContainSubstring("linux-amd64: 12 B of 12 B (100%) at 2 B/s\n"))
+			Ω(output.String()).Should(ContainSubstring("uploaded linux-amd64: 12 B in 9s (1 B/s)\n"))
+		})
+
+		It("reports the size and duration of each upload in the metadata", func() {
+			response, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(response.Metadata).Should(ContainElement(
+				resource.MetadataPair{Name: "uploaded", Value: "linux-amd64: 12 B in 9s (1 B/s)"},
+			))
+		})
+	})
+
+	Context("when only uploading assets", func() {
+		BeforeEach(func() {
+			githubClient.ListAllReleasesReturns([]*github.RepositoryRelease{
+				{
+					ID:         github.Int(111),
+					TagName:    github.String("v0.3.12"),
+					Name:       github.String("the release"),
+					Body:       github.String("this is a great release"),
+					Draft:      github.Bool(false),
+					Prerelease: github.Bool(false),
+				},
+				{
+					ID:         github.Int(112),
+					Draft:      github.Bool(true),
+					Prerelease: github.Bool(false),
+				},
+			}, nil)
+
+			file(filepath.Join(sourcesDir, "tag"), "v0.3.12")
+			file(filepath.Join(sourcesDir, "linux-amd64"), "linux binary")
+
+			request = resource.OutRequest{
+				Params: resource.OutParams{
+					AssetsOnly: true,
+					TagPath:    "tag",
+					Globs:      []string{"linux-*"},
+				},
+			}
+		})
+
+		It("uploads the assets to the release without touching anything else", func() {
+			response, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(1))
+			release, name, _, _ := githubClient.UploadReleaseAssetArgsForCall(0)
+			Ω(*release.ID).Should(Equal(111))
+			Ω(name).Should(Equal("linux-amd64"))
+
+			Ω(githubClient.CreateReleaseCallCount()).Should(Equal(0))
+			Ω(githubClient.UpdateReleaseCallCount()).Should(Equal(0))
+			Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(0))
+
+			Ω(response.Version).Should(Equal(resource.Version{Tag: "v0.3.12"}))
+		})
+
+		It("finds a draft release by its ID", func() {
+			githubClient.GetReleaseReturns(&github.RepositoryRelease{
+				ID:         github.Int(112),
+				Draft:      github.Bool(true),
+				Prerelease: github.Bool(false),
+			}, nil)
+
+			request.Params.TagPath = ""
+			request.Params.Version = &resource.Version{ID: "112"}
+
+			response, err := command.Run(sourcesDir, request)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(githubClient.GetReleaseArgsForCall(0)).Should(Equal(112))
+
+			release, _, _, _ := githubClient.UploadReleaseAssetArgsForCall(0)
+			Ω(*release.ID).Should(Equal(112))
+
+			Ω(response.Version).Should(Equal(resource.Version{ID: "112"}))
+		})
+
+		Context("when another job has already uploaded the same asset", func() {
+			var sleeps []time.Duration
+			var digest string
+
+			BeforeEach(func() {
+				sleeps = nil
+				command = resource.NewOutCommandWithClock(githubClient, ioutil.Discard, time.Now, func(d time.Duration) {
+					sleeps = append(sleeps, d)
+				})
+
+				sum := sha256.Sum256([]byte("linux binary"))
+				digest = "sha256:" + hex.EncodeToString(sum[:])
+
+				githubClient.UploadReleaseAssetReturns(errors.New("already_exists"))
+			})
+
+			It("keeps the asset if it has the same content", func() {
+				githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+					{ID: github.Int(1), Name: github.String("darwin-amd64"), State: github.String("uploaded"), Size: github.Int(3)},
+					{ID: github.Int(2), Name: github.String("linux-amd64"), State: github.String("uploaded"), Size: github.Int(len("linux binary"))},
+				}, nil)
+				githubClient.GetReleaseDetailsReturns(&resource.ReleaseDetails{
+					Assets: []resource.AssetDetails{{ID: 1, Digest: "sha256:abc"}, {ID: 2, Digest: digest}},
+				}, nil)
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.GetReleaseDetailsArgsForCall(0)).Should(Equal(111))
+				Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(1))
+				Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(0))
+			})
+
+			It("replaces the asset if its content is different, even if it is the same size, leaving the others alone", func() {
+				githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+					{ID: github.Int(1), Name: github.String("darwin-amd64"), State: github.String("uploaded"), Size: github.Int(3)},
+					{ID: github.Int(2), Name: github.String("linux-amd64"), State: github.String("uploaded"), Size: github.Int(len("linux binary"))},
+				}, nil)
+				githubClient.GetReleaseDetailsReturns(&resource.ReleaseDetails{
+					Assets: []resource.AssetDetails{{ID: 2, Digest: "sha256:other"}},
+				}, nil)
+				githubClient.UploadReleaseAssetStub = func(github.RepositoryRelease, string, io.Reader, int64) error {
+					if githubClient.UploadReleaseAssetCallCount() == 1 {
+						return errors.New("already_exists")
+					}
+					return nil
+				}
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(2))
+				Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(1))
+				Ω(*githubClient.DeleteReleaseAssetArgsForCall(0).ID).Should(Equal(2))
+			})
+
+			It("waits for an asset that is still being uploaded rather than replacing it", func() {
+				githubClient.ListReleaseAssetsStub = func(github.RepositoryRelease) ([]*github.ReleaseAsset, error) {
+					state := "uploaded"
+					if githubClient.ListReleaseAssetsCallCount() == 1 {
+						state = "new"
+					}
+
+					return []*github.ReleaseAsset{
+						{ID: github.Int(2), Name: github.String("linux-amd64"), State: github.String(state)},
+					}, nil
+				}
+				githubClient.GetReleaseDetailsReturns(&resource.ReleaseDetails{
+					Assets: []resource.AssetDetails{{ID: 2, Digest: digest}},
+				}, nil)
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(sleeps).Should(HaveLen(1))
+				Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(2))
+				Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(0))
+			})
+
+			It("replaces an asset that was never finished uploading", func() {
+				githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+					{ID: github.Int(2), Name: github.String("linux-amd64"), State: github.String("new")},
+				}, nil)
+				githubClient.UploadReleaseAssetStub = func(github.RepositoryRelease, string, io.Reader, int64) error {
+					if githubClient.DeleteReleaseAssetCallCount() == 0 {
+						return errors.New("already_exists")
+					}
+					return nil
+				}
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(sleeps).Should(HaveLen(5))
+				Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(7))
+				Ω(githubClient.DeleteReleaseAssetCallCount()).Should(Equal(1))
+			})
+
+			It("carries on if another job removed the asset first", func() {
+				githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{
+					{ID: github.Int(2), Name: github.String("linux-amd64"), State: github.String("uploaded"), Size: github.Int(0)},
+				}, nil)
+				githubClient.GetReleaseDetailsReturns(&resource.ReleaseDetails{}, nil)
+				githubClient.DeleteReleaseAssetReturns(errors.New("not found"))
+				githubClient.UploadReleaseAssetStub = func(github.RepositoryRelease, string, io.Reader, int64) error {
+					if githubClient.UploadReleaseAssetCallCount() == 1 {
+						return errors.New("already_exists")
+					}
+					return nil
+				}
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(2))
+			})
+
+			It("gives up after retrying", func() {
+				githubClient.ListReleaseAssetsReturns([]*github.ReleaseAsset{}, nil)
+
+				_, err := command.Run(sourcesDir, request)
+				Ω(err).Should(MatchError("already_exists"))
+
+				Ω(githubClient.UploadReleaseAssetCallCount()).Should(Equal(10))
+			})
+		})
+	})
+
+	It("rejects an unknown action", func() {
+		request = resource.OutRequest{
+			Params: resource.OutParams{Action: "destroy"},
+		}
+
+		_, err := command.Run(sourcesDir, request)
+		Ω(err).Should(MatchError("invalid action 'destroy': expected publish, delete or yank"))
 	})
 })
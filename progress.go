@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressInterval is how often a transfer's progress is logged. Progress is
+// logged as whole lines rather than redrawn in place, as build logs are not
+// terminals.
+const progressInterval = 5 * time.Second
+
+// progressReader logs how much of a transfer has been read so far, so that
+// the build log does not go quiet for minutes while a large asset is moved.
+type progressReader struct {
+	reader io.Reader
+	writer io.Writer
+	now    func() time.Time
+
+	name  string
+	total int64
+
+	transferred int64
+	started     time.Time
+	reported    time.Time
+}
+
+func newProgressReader(reader io.Reader, writer io.Writer, now func() time.Time, name string, total int64) *progressReader {
+	started := now()
+
+	return &progressReader{
+		reader: reader,
+		writer: writer,
+		now:    now,
+
+		name:  name,
+		total: total,
+
+		started:  started,
+		reported: started,
+	}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.transferred += int64(n)
+
+	now := r.now()
+	if now.Sub(r.reported) >= progressInterval {
+		r.reported = now
+		r.report(now)
+	}
+
+	return n, err
+}
+
+func (r *progressReader) report(now time.Time) {
+	rate := transferRate(r.transferred, now.Sub(r.started))
+
+	if r.total > 0 {
+		fmt.Fprintf(r.writer, "%s: %s of %s (%d%%) at %s/s\n",
+			r.name, formatBytes(r.transferred), formatBytes(r.total), r.transferred*100/r.total, formatBytes(rate))
+	} else {
+		fmt.Fprintf(r.writer, "%s: %s at %s/s\n", r.name, formatBytes(r.transferred), formatBytes(rate))
+	}
+}
+
+// finish logs and returns how much was transferred and how long it took.
+func (r *progressReader) finish(verb string) transferStats {
+	stats := transferStats{
+		Name:     r.name,
+		Bytes:    r.transferred,
+		Duration: r.now().Sub(r.started),
+	}
+
+	fmt.Fprintf(r.writer, "%s %s\n", verb, stats)
+
+	return stats
+}
+
+type transferStats struct {
+	Name     string
+	Bytes    int64
+	Duration time.Duration
+}
+
+func (s transferStats) String() string {
+	return fmt.Sprintf("%s: %s in %s (%s/s)",
+		s.Name, formatBytes(s.Bytes), s.Duration.Round(time.Millisecond), formatBytes(transferRate(s.Bytes, s.Duration)))
+}
+
+// transferMetadata reports the size and duration of each transfer under the
+// given name, e.g. "downloaded".
+func transferMetadata(name string, transfers []transferStats) []MetadataPair {
+	metadata := []MetadataPair{}
+	for _, transfer := range transfers {
+		metadata = append(metadata, MetadataPair{
+			Name:  name,
+			Value: transfer.String(),
+		})
+	}
+	return metadata
+}
+
+func transferRate(bytes int64, duration time.Duration) int64 {
+	if duration <= 0 {
+		return 0
+	}
+	return int64(float64(bytes) / duration.Seconds())
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,177 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+const yankedNotice = "**This release has been yanked.**"
+
+// deleteRelease deletes the release, and its tag if asked to. The deleted
+// release's version is still returned, as a put must always emit one.
+func (c *OutCommand) deleteRelease(sourceDir string, request OutRequest) (OutResponse, error) {
+	release, err := c.findRelease(sourceDir, request)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	fmt.Fprintf(c.writer, "deleting release %s\n", describeRelease(release))
+
+	err = c.github.DeleteRelease(*release)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	if request.Params.DeleteTag && release.TagName != nil {
+		fmt.Fprintf(c.writer, "deleting tag %s\n", *release.TagName)
+
+		err = c.github.DeleteRef(*release.TagName)
+		if err != nil {
+			return OutResponse{}, err
+		}
+	}
+
+	return OutResponse{
+		Version:  versionFromRelease(release, request.Source.DetailedVersions),
+		Metadata: metadataFromRelease(release, "", request.Source.TagFilter),
+	}, nil
+}
+
+// yankRelease turns the release back into a draft or pre-release, leaving
+// its assets in place, and notes at the top of its body that it was yanked.
+func (c *OutCommand) yankRelease(sourceDir string, request OutRequest) (OutResponse, error) {
+	params := request.Params
+
+	yankAs := params.YankAs
+	if yankAs == "" {
+		yankAs = "prerelease"
+	}
+
+	if yankAs != "draft" && yankAs != "prerelease" {
+		return OutResponse{}, fmt.Errorf("invalid yank_as '%s': expected draft or prerelease", yankAs)
+	}
+
+	notice := yankedNotice
+	if params.YankReasonPath != "" {
+		reason, err := c.fileContents(filepath.Join(sourceDir, params.YankReasonPath))
+		if err != nil {
+			return OutResponse{}, err
+		}
+
+		if reason != "" {
+			notice = notice + " " + reason
+		}
+	}
+
+	release, err := c.findRelease(sourceDir, request)
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	body := ""
+	if release.Body != nil {
+		body = *release.Body
+	}
+
+	if !strings.HasPrefix(body, yankedNotice) {
+		if body == "" {
+			body = notice
+		} else {
+			body = notice + "\n\n" + body
+		}
+	}
+
+	release.Body = github.String(body)
+
+	if yankAs == "draft" {
+		release.Draft = github.Bool(true)
+	} else {
+		release.Prerelease = github.Bool(true)
+	}
+
+	fmt.Fprintf(c.writer, "yanking release %s to a %s\n", describeRelease(release), yankAs)
+
+	release, err = c.github.UpdateRelease(*release, ReleaseOptions{MakeLatest: string(params.MakeLatest)})
+	if err != nil {
+		return OutResponse{}, err
+	}
+
+	return OutResponse{
+		Version:  versionFromRelease(release, request.Source.DetailedVersions),
+		Metadata: metadataFromRelease(release, "", request.Source.TagFilter),
+	}, nil
+}
+
+// findRelease finds the release named by the version param, or else by the
+// tag file. A release is fetched by its ID if the version has one, and
+// otherwise every page of releases is listed, rather than the release being
+// fetched by tag, so that drafts can be found too.
+func (c *OutCommand) findRelease(sourceDir string, request OutRequest) (*github.RepositoryRelease, error) {
+	params := request.Params
+
+	var id int
+	var tag string
+
+	if params.Version != nil && (params.Version.ID != "" || params.Version.Tag != "") {
+		if params.Version.ID != "" {
+			var err error
+			id, err = strconv.Atoi(params.Version.ID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid release id '%s': %s", params.Version.ID, err)
+			}
+		}
+
+		tag = params.Version.Tag
+	} else if params.TagPath != "" {
+		var err error
+		tag, err = c.fileContents(filepath.Join(sourceDir, params.TagPath))
+		if err != nil {
+			return nil, err
+		}
+
+		tag = params.TagPrefix + tag
+	} else {
+		return nil, errors.New("either tag or version must be specified to find the release")
+	}
+
+	if id != 0 {
+		release, err := c.github.GetRelease(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if release == nil {
+			return nil, fmt.Errorf("no release found with id %d", id)
+		}
+
+		return release, nil
+	}
+
+	releases, err := c.github.ListAllReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, release := range releases {
+		if release.TagName != nil && *release.TagName == tag {
+			return release, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no release found with tag '%s'", tag)
+}
+
+// describeRelease names the release by its tag, or by its ID if it is a draft
+// without one.
+func describeRelease(release *github.RepositoryRelease) string {
+	if release.TagName != nil && *release.TagName != "" {
+		return *release.TagName
+	}
+
+	return fmt.Sprintf("with id %d", *release.ID)
+}
@@ -0,0 +1,161 @@
+package resource
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// releaseDocumentSchemaVersion is bumped whenever a field of releaseDocument
+// or assetDocument is changed or removed. Adding fields does not bump it.
+const releaseDocumentSchemaVersion = 1
+
+// releaseDocument is written to release.json by in. It is kept separate from
+// the GitHub client's types so that its fields stay stable.
+type releaseDocument struct {
+	SchemaVersion int `json:"schema_version"`
+
+	ID              int    `json:"id"`
+	Tag             string `json:"tag"`
+	Version         string `json:"version"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	URL             string `json:"url"`
+	TarballURL      string `json:"tarball_url"`
+	ZipballURL      string `json:"zipball_url"`
+	TargetCommitish string `json:"target_commitish"`
+	CommitSHA       string `json:"commit_sha"`
+	Author          string `json:"author"`
+	CreatedAt       string `json:"created_at"`
+	PublishedAt     string `json:"published_at"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+
+	Assets []assetDocument `json:"assets"`
+}
+
+type assetDocument struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	Label              string `json:"label"`
+	Size               int    `json:"size"`
+	ContentType        string `json:"content_type"`
+	DownloadCount      int    `json:"download_count"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	APIURL             string `json:"api_url"`
+	Digest             string `json:"digest"`
+	CreatedAt          string `json:"created_at"`
+	UpdatedAt          string `json:"updated_at"`
+}
+
+func newReleaseDocument(release *github.RepositoryRelease, version string, commitSHA string, assets []*github.ReleaseAsset, details *ReleaseDetails) releaseDocument {
+	document := releaseDocument{
+		SchemaVersion: releaseDocumentSchemaVersion,
+
+		ID:              intValue(release.ID),
+		Tag:             stringValue(release.TagName),
+		Version:         version,
+		Name:            stringValue(release.Name),
+		Body:            stringValue(release.Body),
+		URL:             stringValue(release.HTMLURL),
+		TarballURL:      stringValue(release.TarballURL),
+		ZipballURL:      stringValue(release.ZipballURL),
+		TargetCommitish: stringValue(release.TargetCommitish),
+		CommitSHA:       commitSHA,
+		CreatedAt:       timestampValue(release.CreatedAt),
+		PublishedAt:     timestampValue(release.PublishedAt),
+		Draft:           release.Draft != nil && *release.Draft,
+		Prerelease:      release.Prerelease != nil && *release.Prerelease,
+
+		Assets: []assetDocument{},
+	}
+
+	if release.Author != nil {
+		document.Author = stringValue(release.Author.Login)
+	}
+
+	digests := map[int]string{}
+	for _, asset := range details.Assets {
+		digests[asset.ID] = asset.Digest
+	}
+
+	for _, asset := range assets {
+		document.Assets = append(document.Assets, assetDocument{
+			ID:                 intValue(asset.ID),
+			Name:               stringValue(asset.Name),
+			Label:              stringValue(asset.Label),
+			Size:               intValue(asset.Size),
+			ContentType:        stringValue(asset.ContentType),
+			DownloadCount:      intValue(asset.DownloadCount),
+			BrowserDownloadURL: stringValue(asset.BrowserDownloadURL),
+			APIURL:             stringValue(asset.URL),
+			Digest:             digests[intValue(asset.ID)],
+			CreatedAt:          timestampValue(asset.CreatedAt),
+			UpdatedAt:          timestampValue(asset.UpdatedAt),
+		})
+	}
+
+	return document
+}
+
+// writeReleaseDocument writes release.json and assets.json, along with the
+// fields most often needed on their own as individual files.
+func writeReleaseDocument(destDir string, document releaseDocument) error {
+	err := writeJSONFile(filepath.Join(destDir, "release.json"), document)
+	if err != nil {
+		return err
+	}
+
+	err = writeJSONFile(filepath.Join(destDir, "assets.json"), document.Assets)
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		"name":         document.Name,
+		"url":          document.URL,
+		"published_at": document.PublishedAt,
+	}
+
+	for name, contents := range files {
+		err := ioutil.WriteFile(filepath.Join(destDir, name), []byte(contents), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSONFile(path string, value interface{}) error {
+	contents, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func timestampValue(t *github.Timestamp) string {
+	if t == nil || t.Time.IsZero() {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,259 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/cppforlife/go-semi-semantic/version"
+	"github.com/google/go-github/github"
+)
+
+var defaultFeatureLabels = []string{"feature", "enhancement"}
+var defaultFixLabels = []string{"bug", "fix"}
+
+const defaultNotesTemplate = `{{if .Features}}## Features
+{{range .Features}}
+* {{.Title}} (#{{.Number}}) @{{.Author}}{{end}}
+
+{{end}}{{if .Fixes}}## Fixes
+{{range .Fixes}}
+* {{.Title}} (#{{.Number}}) @{{.Author}}{{end}}
+
+{{end}}{{if .Other}}## Other
+{{range .Other}}
+* {{.Title}} (#{{.Number}}) @{{.Author}}{{end}}
+{{end}}`
+
+// pullRequestPatterns find the pull request number in the first line of a
+// merge commit, or of a squashed or rebased pull request's commit.
+var pullRequestPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^Merge pull request #(\d+)`),
+	regexp.MustCompile(`\(#(\d+)\)$`),
+}
+
+type releaseNotes struct {
+	Tag         string
+	PreviousTag string
+
+	Features []pullRequestNote
+	Fixes    []pullRequestNote
+	Other    []pullRequestNote
+}
+
+type pullRequestNote struct {
+	Number int
+	Title  string
+	Author string
+	URL    string
+	Labels []string
+}
+
+// notesHead is what to compare up to when no commitish is given: the tag if
+// it already exists, or else the default branch, which is where GitHub will
+// create the tag.
+func (c *OutCommand) notesHead(tag string) (string, error) {
+	ref, err := c.github.GetRef(tag)
+	if err != nil {
+		return "", err
+	}
+
+	if ref != nil {
+		return tag, nil
+	}
+
+	return c.github.GetDefaultBranch()
+}
+
+// generateNotes renders notes for the pull requests merged since the release
+// that precedes the tag, ordered the same way as check orders versions.
+func (c *OutCommand) generateNotes(sourceDir string, request OutRequest, tag string, head string, releases []*github.RepositoryRelease) (string, error) {
+	params := request.Params
+
+	notesTemplate := defaultNotesTemplate
+	if params.NotesTemplatePath != "" {
+		contents, err := ioutil.ReadFile(filepath.Join(sourceDir, params.NotesTemplatePath))
+		if err != nil {
+			return "", err
+		}
+		notesTemplate = string(contents)
+	}
+
+	tmpl, err := template.New("notes").Parse(notesTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	notes := releaseNotes{Tag: tag}
+
+	previous, err := previousRelease(request.Source, tag, releases)
+	if err != nil {
+		return "", err
+	}
+
+	if previous == nil {
+		fmt.Fprintf(c.writer, "no release found before %s; generating empty notes\n", tag)
+	} else {
+		notes.PreviousTag = *previous.TagName
+
+		if head == "" {
+			head, err = c.notesHead(tag)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		fmt.Fprintf(c.writer, "generating notes for changes between %s and %s\n", notes.PreviousTag, head)
+
+		comparison, err := c.github.CompareCommits(notes.PreviousTag, head)
+		if err != nil {
+			return "", err
+		}
+
+		featureLabels := params.FeatureLabels
+		if len(featureLabels) == 0 {
+			featureLabels = defaultFeatureLabels
+		}
+
+		fixLabels := params.FixLabels
+		if len(fixLabels) == 0 {
+			fixLabels = defaultFixLabels
+		}
+
+		for _, number := range pullRequestNumbers(comparison.Commits) {
+			issue, err := c.github.GetIssue(number)
+			if err != nil {
+				return "", err
+			}
+
+			note := pullRequestNoteFromIssue(number, issue)
+
+			switch {
+			case hasAnyLabel(note, featureLabels):
+				notes.Features = append(notes.Features, note)
+			case hasAnyLabel(note, fixLabels):
+				notes.Fixes = append(notes.Fixes, note)
+			default:
+				notes.Other = append(notes.Other, note)
+			}
+		}
+	}
+
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, notes)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(rendered.String()), nil
+}
+
+// previousRelease finds the newest published release, of the types the
+// source is configured for, whose version is older than the tag's.
+func previousRelease(source Source, tag string, releases []*github.RepositoryRelease) (*github.RepositoryRelease, error) {
+	versionParser, err := newVersionParser(source.TagFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := version.NewVersionFromString(versionParser.parse(tag))
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the version of tag '%s' to find the previous release: %s", tag, err)
+	}
+
+	var candidates []*github.RepositoryRelease
+	for _, release := range releases {
+		if release.Draft == nil || *release.Draft || release.TagName == nil || release.Prerelease == nil {
+			continue
+		}
+
+		if !releaseTypeMatches(source, release) {
+			continue
+		}
+
+		v, err := version.NewVersionFromString(versionParser.parse(*release.TagName))
+		if err != nil || !v.IsLt(current) {
+			continue
+		}
+
+		candidates = append(candidates, release)
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	sortReleases(candidates, versionParser)
+
+	return candidates[len(candidates)-1], nil
+}
+
+func pullRequestNumbers(commits []github.RepositoryCommit) []int {
+	numbers := []int{}
+	seen := map[int]bool{}
+
+	for _, commit := range commits {
+		if commit.Commit == nil || commit.Commit.Message == nil {
+			continue
+		}
+
+		subject := strings.TrimSpace(strings.SplitN(*commit.Commit.Message, "\n", 2)[0])
+
+		for _, pattern := range pullRequestPatterns {
+			matches := pattern.FindStringSubmatch(subject)
+			if len(matches) == 0 {
+				continue
+			}
+
+			number, err := strconv.Atoi(matches[1])
+			if err == nil && !seen[number] {
+				seen[number] = true
+				numbers = append(numbers, number)
+			}
+			break
+		}
+	}
+
+	return numbers
+}
+
+func pullRequestNoteFromIssue(number int, issue *github.Issue) pullRequestNote {
+	note := pullRequestNote{Number: number}
+
+	if issue.Title != nil {
+		note.Title = *issue.Title
+	}
+
+	if issue.User != nil && issue.User.Login != nil {
+		note.Author = *issue.User.Login
+	}
+
+	if issue.HTMLURL != nil {
+		note.URL = *issue.HTMLURL
+	}
+
+	for _, label := range issue.Labels {
+		if label.Name != nil {
+			note.Labels = append(note.Labels, *label.Name)
+		}
+	}
+
+	return note
+}
+
+func hasAnyLabel(note pullRequestNote, labels []string) bool {
+	for _, label := range note.Labels {
+		for _, wanted := range labels {
+			if strings.EqualFold(label, wanted) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package resource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// buildMetadataVariables are the environment variables Concourse sets with
+// the build's metadata when running a put.
+var buildMetadataVariables = []string{
+	"BUILD_ID",
+	"BUILD_NAME",
+	"BUILD_JOB_NAME",
+	"BUILD_PIPELINE_NAME",
+	"BUILD_TEAM_NAME",
+	"ATC_EXTERNAL_URL",
+}
+
+type releaseTemplateData struct {
+	Tag       string
+	Version   string
+	Commitish string
+	Timestamp time.Time
+	Assets    []releaseTemplateAsset
+	Env       map[string]string
+}
+
+type releaseTemplateAsset struct {
+	Name   string
+	Size   int64
+	SHA256 string
+}
+
+func newReleaseTemplateData(source Source, tag string, commitish string, assetPaths []string, now time.Time) (releaseTemplateData, error) {
+	versionParser, err := newVersionParser(source.TagFilter)
+	if err != nil {
+		return releaseTemplateData{}, err
+	}
+
+	data := releaseTemplateData{
+		Tag:       tag,
+		Version:   versionParser.parse(tag),
+		Commitish: commitish,
+		Timestamp: now.UTC(),
+		Assets:    []releaseTemplateAsset{},
+		Env:       map[string]string{},
+	}
+
+	for _, name := range buildMetadataVariables {
+		data.Env[name] = os.Getenv(name)
+	}
+
+	for _, path := range assetPaths {
+		asset, err := releaseTemplateAssetFromFile(path)
+		if err != nil {
+			return releaseTemplateData{}, err
+		}
+
+		data.Assets = append(data.Assets, asset)
+	}
+
+	return data, nil
+}
+
+func releaseTemplateAssetFromFile(path string) (releaseTemplateAsset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return releaseTemplateAsset{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return releaseTemplateAsset{}, err
+	}
+
+	return releaseTemplateAsset{
+		Name:   filepath.Base(path),
+		Size:   size,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+func renderReleaseTemplate(name string, text string, data releaseTemplateData) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered bytes.Buffer
+	err = tmpl.Execute(&rendered, data)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(rendered.String()), nil
+}
@@ -1,5 +1,12 @@
 package resource
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
 type Source struct {
 	Owner      string `json:"owner"`
 	Repository string `json:"repository"`
@@ -7,6 +14,8 @@ type Source struct {
 	// Deprecated; use Owner instead
 	User string `json:"user"`
 
+	Repositories []string `json:"repositories"`
+
 	GitHubAPIURL     string `json:"github_api_url"`
 	GitHubUploadsURL string `json:"github_uploads_url"`
 	AccessToken      string `json:"access_token"`
@@ -16,6 +25,16 @@ type Source struct {
 	Insecure         bool   `json:"insecure"`
 
 	TagFilter string `json:"tag_filter"`
+
+	Authors           []string `json:"authors"`
+	CommitishFilter   string   `json:"commitish_filter"`
+	BodyExcludeFilter string   `json:"body_exclude_filter"`
+
+	MinAge string `json:"min_age"`
+
+	DetailedVersions bool `json:"detailed_versions"`
+
+	GitHubLatest bool `json:"github_latest"`
 }
 
 type CheckRequest struct {
@@ -48,9 +67,18 @@ type InRequest struct {
 }
 
 type InParams struct {
-	Globs                []string `json:"globs"`
-	IncludeSourceTarball bool     `json:"include_source_tarball"`
-	IncludeSourceZip     bool     `json:"include_source_zip"`
+	Globs                []string            `json:"globs"`
+	AssetRegex           string              `json:"asset_regex"`
+	FailOnUnmatchedGlobs *bool               `json:"fail_on_unmatched_globs"`
+	IncludeSourceTarball bool                `json:"include_source_tarball"`
+	IncludeSourceZip     bool                `json:"include_source_zip"`
+	IncludeSource        bool                `json:"include_source"`
+	ArchiveFormat        string              `json:"archive_format"`
+	IncludeSourceGit     bool                `json:"include_source_git"`
+	SkipDownload         bool                `json:"skip_download"`
+	IncludeDetails       bool                `json:"include_details"`
+	Executable           []string            `json:"executable"`
+	Modes                map[string]FileMode `json:"modes"`
 }
 
 type InResponse struct {
@@ -64,13 +92,95 @@ type OutRequest struct {
 }
 
 type OutParams struct {
+	Action string `json:"action"`
+
 	NamePath      string `json:"name"`
 	BodyPath      string `json:"body"`
+	NameTemplate  string `json:"name_template"`
+	BodyTemplate  string `json:"body_template"`
+	BodyMode      string `json:"body_mode"`
+	BodySection   string `json:"body_section"`
 	TagPath       string `json:"tag"`
 	CommitishPath string `json:"commitish"`
 	TagPrefix     string `json:"tag_prefix"`
 
-	Globs []string `json:"globs"`
+	MakeLatest             MakeLatest `json:"make_latest"`
+	DiscussionCategoryName string     `json:"discussion_category_name"`
+
+	CreateTag      bool   `json:"create_tag"`
+	TagMessagePath string `json:"tag_message"`
+	TaggerName     string `json:"tagger_name"`
+	TaggerEmail    string `json:"tagger_email"`
+
+	Version        *Version `json:"version"`
+	DeleteTag      bool     `json:"delete_tag"`
+	YankAs         string   `json:"yank_as"`
+	YankReasonPath string   `json:"yank_reason"`
+
+	GenerateNotes     bool     `json:"generate_notes"`
+	NotesTemplatePath string   `json:"notes_template"`
+	FeatureLabels     []string `json:"feature_labels"`
+	FixLabels         []string `json:"fix_labels"`
+
+	Globs      []string `json:"globs"`
+	AssetsOnly bool     `json:"assets_only"`
+
+	Retain *RetentionPolicy `json:"retain"`
+}
+
+// RetentionPolicy describes which older releases to prune after publishing.
+type RetentionPolicy struct {
+	Count      int    `json:"count"`
+	MaxAge     string `json:"max_age"`
+	Match      string `json:"match"`
+	DeleteTags bool   `json:"delete_tags"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+// MakeLatest is whether a release should be marked as the repository's latest
+// release: "true", "false" or "legacy". It may also be given as a boolean.
+type MakeLatest string
+
+func (m *MakeLatest) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*m = MakeLatest(strconv.FormatBool(b))
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*m = MakeLatest(s)
+	return nil
+}
+
+// FileMode is the permissions to give a file. It is given as a string of
+// octal digits such as "0644", or as the number YAML reads an unquoted 0644
+// as.
+type FileMode os.FileMode
+
+func (m *FileMode) UnmarshalJSON(data []byte) error {
+	var mode uint64
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		mode, err = strconv.ParseUint(s, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid file mode '%s': expected octal permissions such as 0644", s)
+		}
+	} else if err := json.Unmarshal(data, &mode); err != nil {
+		return fmt.Errorf("invalid file mode %s: expected octal permissions such as 0644", data)
+	}
+
+	if mode > 0777 {
+		return fmt.Errorf("invalid file mode %#o: expected octal permissions such as 0644", mode)
+	}
+
+	*m = FileMode(mode)
+	return nil
 }
 
 type OutResponse struct {
@@ -79,8 +189,11 @@ type OutResponse struct {
 }
 
 type Version struct {
-	Tag string `json:"tag,omitempty"`
-	ID  string `json:"id,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	ID          string `json:"id,omitempty"`
+	PublishedAt string `json:"published_at,omitempty"`
+	CommitSHA   string `json:"commit_sha,omitempty"`
+	Repository  string `json:"repository,omitempty"`
 }
 
 type MetadataPair struct {
@@ -0,0 +1,165 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/cppforlife/go-semi-semantic/version"
+	"github.com/google/go-github/github"
+)
+
+type retentionPolicy struct {
+	count      int
+	maxAge     time.Duration
+	match      *regexp.Regexp
+	deleteTags bool
+	dryRun     bool
+
+	source        Source
+	versionParser versionParser
+}
+
+func newRetentionPolicy(policy RetentionPolicy, source Source) (*retentionPolicy, error) {
+	if policy.Count < 0 {
+		return nil, fmt.Errorf("invalid retain count %d: must not be negative", policy.Count)
+	}
+
+	if policy.Count == 0 && policy.MaxAge == "" {
+		return nil, errors.New("retain requires a count or a max_age")
+	}
+
+	versionParser, err := newVersionParser(source.TagFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	retention := &retentionPolicy{
+		count:      policy.Count,
+		deleteTags: policy.DeleteTags,
+		dryRun:     policy.DryRun,
+
+		source:        source,
+		versionParser: versionParser,
+	}
+
+	if policy.MaxAge != "" {
+		retention.maxAge, err = time.ParseDuration(policy.MaxAge)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if policy.Match != "" {
+		retention.match, err = regexp.Compile(policy.Match)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return retention, nil
+}
+
+// candidates returns the releases the policy applies to, from oldest to
+// newest: published releases of the type the source is configured for, whose
+// tag matches and parses to a version. Anything else is never pruned.
+func (rp *retentionPolicy) candidates(releases []*github.RepositoryRelease) []*github.RepositoryRelease {
+	var candidates []*github.RepositoryRelease
+
+	for _, release := range releases {
+		if release.Draft == nil || *release.Draft || release.TagName == nil || release.Prerelease == nil {
+			continue
+		}
+
+		if !releaseTypeMatches(rp.source, release) {
+			continue
+		}
+
+		if rp.match != nil && !rp.match.MatchString(*release.TagName) {
+			continue
+		}
+
+		if _, err := version.NewVersionFromString(rp.versionParser.parse(*release.TagName)); err != nil {
+			continue
+		}
+
+		candidates = append(candidates, release)
+	}
+
+	sortReleases(candidates, rp.versionParser)
+
+	return candidates
+}
+
+// expired returns the releases outside the window: beyond the newest count,
+// or published longer than max_age ago. The release that was just published
+// is never expired.
+func (rp *retentionPolicy) expired(releases []*github.RepositoryRelease, published *github.RepositoryRelease, now time.Time) []*github.RepositoryRelease {
+	candidates := rp.candidates(releases)
+
+	var expired []*github.RepositoryRelease
+	for i, release := range candidates {
+		if published.ID != nil && release.ID != nil && *release.ID == *published.ID {
+			continue
+		}
+
+		newerReleases := len(candidates) - 1 - i
+		if rp.count > 0 && newerReleases >= rp.count {
+			expired = append(expired, release)
+			continue
+		}
+
+		if rp.maxAge > 0 {
+			publishedAt := release.PublishedAt
+			if publishedAt == nil {
+				publishedAt = release.CreatedAt
+			}
+
+			if publishedAt != nil && now.Sub(publishedAt.Time) > rp.maxAge {
+				expired = append(expired, release)
+			}
+		}
+	}
+
+	return expired
+}
+
+func (c *OutCommand) pruneReleases(retention *retentionPolicy, published *github.RepositoryRelease) error {
+	releases, err := c.github.ListAllReleases()
+	if err != nil {
+		return err
+	}
+
+	expired := retention.expired(releases, published, c.now())
+
+	if len(expired) == 0 {
+		fmt.Fprintf(c.writer, "no releases to prune\n")
+		return nil
+	}
+
+	for _, release := range expired {
+		if retention.dryRun {
+			fmt.Fprintf(c.writer, "would prune release %s\n", *release.TagName)
+			continue
+		}
+
+		fmt.Fprintf(c.writer, "pruning release %s\n", *release.TagName)
+
+		err := c.github.DeleteRelease(*release)
+		if err != nil {
+			return err
+		}
+
+		if retention.deleteTags {
+			fmt.Fprintf(c.writer, "deleting tag %s\n", *release.TagName)
+
+			err := c.github.DeleteRef(*release.TagName)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package resource
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// archiveRef is the ref to archive the release's source at: its tag once it
+// has been published, or else the commitish the tag will be created from.
+func archiveRef(release *github.RepositoryRelease) (string, error) {
+	if release.Draft != nil && !*release.Draft && release.TagName != nil && *release.TagName != "" {
+		return *release.TagName, nil
+	}
+
+	if release.TargetCommitish != nil && *release.TargetCommitish != "" {
+		return *release.TargetCommitish, nil
+	}
+
+	return "", errors.New("could not determine the tag or commitish to fetch the source of the release at")
+}
+
+// fetchSource downloads the source archive at the ref and extracts it into
+// source/, without the top-level directory GitHub wraps it in. source/ only
+// appears once the whole archive has been extracted.
+func (c *InCommand) fetchSource(destDir string, ref string, format string) error {
+	var link func(string) (*url.URL, error)
+	var extract func(string, string) error
+
+	switch format {
+	case "", "tarball":
+		link, extract = c.github.GetTarballLink, extractTarball
+	case "zipball":
+		link, extract = c.github.GetZipballLink, extractZipball
+	default:
+		return fmt.Errorf("invalid archive_format '%s': expected tarball or zipball", format)
+	}
+
+	u, err := link(ref)
+	if err != nil {
+		return err
+	}
+
+	archive, err := ioutil.TempFile(destDir, ".source-archive")
+	if err != nil {
+		return err
+	}
+	archive.Close()
+	defer os.Remove(archive.Name())
+
+	fmt.Fprintf(c.writer, "downloading source at %s to source\n", ref)
+
+	err = c.downloadFile(u.String(), archive.Name())
+	if err != nil {
+		return err
+	}
+
+	return populateAtomically(filepath.Join(destDir, "source"), func(dir string) error {
+		return extract(archive.Name(), dir)
+	})
+}
+
+func extractTarball(archivePath string, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	err = os.MkdirAll(destDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path, ok, err := archiveEntryPath(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg, tar.TypeRegA:
+			err = writeArchiveFile(path, os.FileMode(header.Mode), reader)
+		case tar.TypeSymlink:
+			err = writeArchiveSymlink(path, header.Linkname)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZipball(archivePath string, destDir string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	err = os.MkdirAll(destDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range reader.File {
+		path, ok, err := archiveEntryPath(destDir, entry.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if entry.FileInfo().IsDir() {
+			err = os.MkdirAll(path, 0755)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		contents, err := entry.Open()
+		if err != nil {
+			return err
+		}
+
+		if entry.Mode()&os.ModeSymlink != 0 {
+			var target []byte
+			target, err = ioutil.ReadAll(contents)
+			if err == nil {
+				err = writeArchiveSymlink(path, string(target))
+			}
+		} else {
+			err = writeArchiveFile(path, entry.Mode(), contents)
+		}
+
+		contents.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// archiveEntryPath strips the archive's top-level directory from the entry's
+// name and returns where it should be extracted to. Entries outside of the
+// top-level directory, such as tar's global header, are skipped.
+func archiveEntryPath(destDir string, name string) (string, bool, error) {
+	parts := strings.SplitN(strings.TrimPrefix(name, "./"), "/", 2)
+	if len(parts) < 2 || parts[1] == "" {
+		return "", false, nil
+	}
+
+	path := filepath.Join(destDir, filepath.FromSlash(parts[1]))
+	if !strings.HasPrefix(path, filepath.Clean(destDir)+string(os.PathSeparator)) {
+		return "", false, fmt.Errorf("archive entry '%s' is outside of the archive", name)
+	}
+
+	return path, true, nil
+}
+
+func writeArchiveFile(path string, mode os.FileMode, contents io.Reader) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, contents)
+	return err
+}
+
+func writeArchiveSymlink(path string, target string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.Symlink(target, path)
+}
@@ -0,0 +1,34 @@
+package resource
+
+import "github.com/google/go-github/github"
+
+// maxTagDepth limits how many annotated tags pointing at other annotated tags
+// are followed before giving up on finding the object they point to.
+const maxTagDepth = 10
+
+// dereferenceTag follows a tag ref through any annotated tag objects to the
+// object it ultimately points to, usually a commit. The first annotated tag
+// object found on the way is returned too, or nil for a lightweight tag.
+func dereferenceTag(gh GitHub, reference *github.Reference) (*github.GitObject, *github.Tag, error) {
+	var annotatedTag *github.Tag
+
+	object := reference.Object
+	for depth := 0; depth < maxTagDepth && isTagObject(object); depth++ {
+		tagObject, err := gh.GetTag(*object.SHA)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if annotatedTag == nil {
+			annotatedTag = tagObject
+		}
+
+		object = tagObject.Object
+	}
+
+	return object, annotatedTag, nil
+}
+
+func isTagObject(object *github.GitObject) bool {
+	return object != nil && object.Type != nil && *object.Type == "tag"
+}
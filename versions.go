@@ -2,8 +2,11 @@ package resource
 
 import (
 	"regexp"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/cppforlife/go-semi-semantic/version"
 	"github.com/google/go-github/github"
 )
 
@@ -32,10 +35,107 @@ func (vp *versionParser) parse(tag string) string {
 	return ""
 }
 
-func versionFromRelease(release *github.RepositoryRelease) Version {
+// sortReleases orders releases from oldest to newest by the version parsed
+// from their tag. Releases with the same version are ordered by ID so that the
+// order is stable between runs.
+func sortReleases(releases []*github.RepositoryRelease, vp versionParser) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		first, err := version.NewVersionFromString(vp.parse(*releases[i].TagName))
+		if err != nil {
+			return true
+		}
+
+		second, err := version.NewVersionFromString(vp.parse(*releases[j].TagName))
+		if err != nil {
+			return false
+		}
+
+		if first.IsEq(second) {
+			return *releases[i].ID < *releases[j].ID
+		}
+
+		return first.IsLt(second)
+	})
+}
+
+var commitSHAPattern = regexp.MustCompile("^[0-9a-f]{40}$")
+
+var semanticVersionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+type versionComponents struct {
+	Major      string
+	Minor      string
+	Patch      string
+	PreRelease string
+}
+
+// parseVersionComponents splits a version parsed from a tag into its semver
+// components. A missing minor or patch version is taken to be 0.
+func parseVersionComponents(v string) (versionComponents, bool) {
+	matches := semanticVersionPattern.FindStringSubmatch(v)
+	if matches == nil {
+		return versionComponents{}, false
+	}
+
+	components := versionComponents{
+		Major:      matches[1],
+		Minor:      matches[2],
+		Patch:      matches[3],
+		PreRelease: matches[4],
+	}
+
+	if components.Minor == "" {
+		components.Minor = "0"
+	}
+
+	if components.Patch == "" {
+		components.Patch = "0"
+	}
+
+	return components, true
+}
+
+func versionFromRelease(release *github.RepositoryRelease, detailed bool) Version {
+	if detailed {
+		return detailedVersionFromRelease(release)
+	}
+
 	if *release.Draft {
 		return Version{ID: strconv.Itoa(*release.ID)}
 	} else {
 		return Version{Tag: *release.TagName}
 	}
 }
+
+// detailedVersionFromRelease identifies the release by both tag and ID, so
+// that a release which is deleted and recreated with the same tag is seen as
+// a new version.
+func detailedVersionFromRelease(release *github.RepositoryRelease) Version {
+	version := Version{ID: strconv.Itoa(*release.ID)}
+
+	if release.TagName != nil {
+		version.Tag = *release.TagName
+	}
+
+	if release.PublishedAt != nil {
+		version.PublishedAt = release.PublishedAt.UTC().Format(time.RFC3339)
+	}
+
+	if release.TargetCommitish != nil && commitSHAPattern.MatchString(*release.TargetCommitish) {
+		version.CommitSHA = *release.TargetCommitish
+	}
+
+	return version
+}
+
+// releaseMatchesVersion matches on the release ID when the version has one, so
+// that a release recreated under the same tag is not mistaken for it, and
+// otherwise falls back to the tag, so that versions emitted before detailed
+// versions were enabled are still recognised.
+func releaseMatchesVersion(release *github.RepositoryRelease, version Version) bool {
+	if version.ID != "" {
+		return release.ID != nil && strconv.Itoa(*release.ID) == version.ID
+	}
+
+	return version.Tag != "" && release.TagName != nil && *release.TagName == version.Tag
+}